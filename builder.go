@@ -14,24 +14,51 @@ import (
 type Action string
 
 const (
-	actionSelect Action = "select"
-	actionInsert Action = "insert"
-	actionUpdate Action = "update"
-	actionDelete Action = "delete"
+	ActionSelect Action = "select"
+	ActionInsert Action = "insert"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
 )
 
 type Builder struct {
-	action   Action
-	dialect  string
-	table    Template
-	columns  Templates
-	joins    Templates
-	where    Conditions
-	orderBy  []string
-	paging   Template
-	groupBy  []string
-	having   Conditions
-	distinct bool
+	action    Action
+	dialect   string
+	table     Template
+	columns   Templates
+	joins     []join
+	compounds []compound
+	ctes      []cte
+	upsert    *Upsert
+	returning []string
+	key       string
+	source    *Builder
+	using     Templates
+	naming    Naming
+	where     Conditions
+	orderBy   []string
+	limit     int
+	offset    int
+	groupBy   []string
+	having    Conditions
+	distinct  bool
+}
+
+type join struct {
+	kind  string
+	table Template
+	on    Template
+}
+
+type compound struct {
+	op    string
+	query *Builder
+}
+
+type cte struct {
+	name      string
+	columns   []string
+	query     *Builder
+	recursive bool
 }
 
 func NewBuilder(options ...BuilderOptionFunc) *Builder {
@@ -50,26 +77,30 @@ func (b *Builder) Apply(options ...BuilderOptionFunc) *Builder {
 
 func (b *Builder) Build() Template {
 	d := GetDialect(b.dialect)
+	return b.build(d).Rebind(d)
+}
+
+func (b *Builder) build(d Dialect) Template {
 	t := Template{}
+	output, returning := b.returningClauses(d)
 	switch b.action {
-	case actionSelect:
-		columns := make([]string, 0, len(b.columns.Formats()))
-		for _, c := range b.columns.Formats() {
-			columns = append(columns, d.Quote(c))
+	case ActionSelect:
+		columns := make(Templates, 0, len(b.columns))
+		for _, c := range b.columns {
+			columns = append(columns, c.quote(d))
+		}
+		if len(columns) == 0 {
+			columns = append(columns, NewTemplate("*"))
 		}
 		if b.distinct {
-			t = t.Appendf(fmt.Sprintf("select distinct %s from %s",
-				strings.Join(columns, ","),
-				d.Quote(b.table.Format),
-			), append(append([]interface{}{}, b.columns.Values()...), b.table.Values...))
+			t = t.Appendf("select distinct ")
 		} else {
-			t = t.Appendf(fmt.Sprintf("select %s from %s",
-				strings.Join(columns, ","),
-				d.Quote(b.table.Format),
-			), b.columns.Values()...)
+			t = t.Appendf("select ")
 		}
-		if len(b.joins) > 0 {
-			t = t.Append(b.joins.Join(" ", "", ""))
+		t = t.Append(columns.Join(",", "", ""))
+		t = t.Appendf(" from ").Append(b.table.quote(d))
+		for _, j := range b.joins {
+			t = t.Append(j.build(d))
 		}
 		if len(b.where) > 0 {
 			t = t.Appendf(" where ").Append(b.where.JoinAnd())
@@ -80,46 +111,213 @@ func (b *Builder) Build() Template {
 		if len(b.having) > 0 {
 			t = t.Appendf(" having ").Append(b.having.JoinAnd())
 		}
+		for _, c := range b.compounds {
+			t = t.Appendf(" " + c.op + " ").Append(c.query.build(d))
+		}
 		if len(b.orderBy) > 0 {
 			t = t.Appendf(fmt.Sprintf(" order by %s", strings.Join(b.orderBy, ", ")))
 		}
-		if len(b.paging.Format) > 0 {
-			t = t.Appendf(" ").Append(b.paging)
+		if b.limit > 0 || b.offset > 0 {
+			t = d.Paging(t, Page{
+				Limit:    b.limit,
+				Offset:   b.offset,
+				Ordered:  len(b.orderBy) > 0,
+				Compound: len(b.compounds) > 0,
+			})
+		}
+	case ActionInsert:
+		if b.source != nil {
+			columns := make([]string, 0, len(b.columns))
+			for _, c := range b.columns {
+				columns = append(columns, d.QuoteIdent(c.Format))
+			}
+			t = t.Appendf(fmt.Sprintf("insert into %s(%s)%s ", d.QuoteIdent(b.table.Format), strings.Join(columns, ","), clause(output)))
+			t = t.Append(b.source.build(d))
+			break
 		}
-	case actionInsert:
-		t = t.Appendf(fmt.Sprintf("insert into %s(%s) values(%s)",
-			b.table,
-			b.columns.Formats(),
+		if b.upsert != nil {
+			u := *b.upsert
+			u.Table = b.table.Format
+			u.Columns = b.columns.Formats()
+			u.Rows = [][]interface{}{b.columns.Values()}
+			if len(u.Conflict) == 0 && b.key != "" {
+				u.Conflict = []string{b.key}
+			}
+			u.Output = output
+			t = t.Append(d.Upsert(u))
+			break
+		}
+		columns := make([]string, 0, len(b.columns))
+		for _, c := range b.columns {
+			columns = append(columns, d.QuoteIdent(c.Format))
+		}
+		t = t.Appendf(fmt.Sprintf("insert into %s(%s)%s values(%s)",
+			d.QuoteIdent(b.table.Format),
+			strings.Join(columns, ","),
+			clause(output),
 			strings.Join(repeatString("?", len(b.columns)), ","),
 		), b.columns.Values()...)
-		if len(b.where) > 0 {
-			t = t.Appendf(" where ").Append(b.where.JoinAnd())
-		}
-	case actionUpdate:
+	case ActionUpdate:
 		pairs := make([]string, 0, len(b.columns))
 		for _, c := range b.columns {
-			pairs = append(pairs, fmt.Sprintf("%s = ?", GetDialect(b.dialect).Quote(c.Format)))
+			pairs = append(pairs, fmt.Sprintf("%s = ?", d.QuoteIdent(c.Format)))
 		}
-		t.Appendf(fmt.Sprintf("update %s set %s",
-			b.table,
+		if len(b.using) > 0 {
+			m := b.multiTable(d)
+			m.Set = NewTemplate(strings.Join(pairs, ","), b.columns.Values()...)
+			m.Columns = b.columns.Formats()
+			m.Output = output
+			t = t.Append(d.UpdateFrom(m))
+			break
+		}
+		t = t.Appendf(fmt.Sprintf("update %s set %s%s",
+			d.QuoteIdent(b.table.Format),
 			strings.Join(pairs, ","),
+			clause(output),
 		), b.columns.Values()...)
 		if len(b.where) > 0 {
 			t = t.Appendf(" where ").Append(b.where.JoinAnd())
 		}
-	case actionDelete:
-		t = t.Appendf(fmt.Sprintf("delete from %s", b.table))
+	case ActionDelete:
+		if len(b.using) > 0 {
+			m := b.multiTable(d)
+			m.Output = output
+			t = t.Append(d.DeleteUsing(m))
+			break
+		}
+		t = t.Appendf(fmt.Sprintf("delete from %s%s", d.QuoteIdent(b.table.Format), clause(output)))
 		if len(b.where) > 0 {
 			t = t.Appendf(" where ").Append(b.where.JoinAnd())
 		}
 	}
-	return t
+	t = t.Appendf(clause(returning))
+	if len(b.ctes) > 0 {
+		t = b.buildWith(d).Append(t)
+	}
+	return t.expand(d)
+}
+
+// returningClauses returns the output and returning clauses of the returning columns, if any.
+func (b *Builder) returningClauses(d Dialect) (string, string) {
+	if len(b.returning) == 0 || b.action == ActionSelect {
+		return "", ""
+	}
+	return d.Returning(b.action, b.returning)
+}
+
+// clause returns s preceded by a space, or "" if s is empty.
+func clause(s string) string {
+	if s == "" {
+		return ""
+	}
+	return " " + s
+}
+
+func (b *Builder) multiTable(d Dialect) MultiTable {
+	m := MultiTable{
+		Table:  b.table.quote(d),
+		Target: d.QuoteIdent(b.table.Format),
+		Where:  b.where.JoinAnd(),
+	}
+	if b.table.alias != "" {
+		m.Target = d.QuoteIdent(b.table.alias)
+	}
+	for _, u := range b.using {
+		m.Others = append(m.Others, u.quote(d))
+	}
+	return m
+}
+
+func (b *Builder) buildWith(d Dialect) Template {
+	recursive := false
+	tt := make(Templates, 0, len(b.ctes))
+	for _, c := range b.ctes {
+		if c.recursive {
+			recursive = true
+		}
+		name := d.QuoteIdent(c.name)
+		if len(c.columns) > 0 {
+			columns := make([]string, 0, len(c.columns))
+			for _, column := range c.columns {
+				columns = append(columns, d.QuoteIdent(column))
+			}
+			name += "(" + strings.Join(columns, ",") + ")"
+		}
+		tt = append(tt, NewTemplate(name+" as (").Append(c.query.build(d)).Appendf(")"))
+	}
+	return tt.Join(", ", d.With(recursive)+" ", " ")
 }
 
+// Query runs the query and binds its rows into i. For an insert with Returning
+// on a dialect without returning clause, the first returning column of i is
+// set from the last insert id instead.
 func (b *Builder) Query(ctx context.Context, db DB, i interface{}) error {
+	b = b.returningKey()
+	if b.queryReturnsLastInsertID() {
+		return b.queryLastInsertID(ctx, db, i)
+	}
 	return db.Query(ctx, b.Build(), i)
 }
 
+// returningKey returns a copy of an InsertStruct builder without returning
+// columns that returns the key field of the struct, or b itself.
+func (b *Builder) returningKey() *Builder {
+	if b.action != ActionInsert || len(b.returning) > 0 || b.key == "" {
+		return b
+	}
+	c := *b
+	c.returning = []string{b.key}
+	return &c
+}
+
+// queryReturnsLastInsertID reports whether the returning columns fall back to
+// the last insert id because the dialect does not support returning.
+func (b *Builder) queryReturnsLastInsertID() bool {
+	if len(b.returning) == 0 || b.action == ActionSelect {
+		return false
+	}
+	output, returning := b.returningClauses(GetDialect(b.dialect))
+	return output == "" && returning == ""
+}
+
+func (b *Builder) queryLastInsertID(ctx context.Context, db DB, i interface{}) error {
+	if b.action != ActionInsert {
+		return newError("builder", "dialect does not support returning")
+	}
+	result, err := db.Exec(ctx, b.Build())
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	rv := reflect.ValueOf(i)
+	if rv.Kind() != reflect.Ptr {
+		return newError("builder", "require pointer type")
+	}
+	rv = reflectutil.DeepUnrefAndNewValue(rv)
+	if rv.Kind() == reflect.Struct {
+		fieldMap := getFieldMap(rv.Addr().Interface(), b.naming)
+		f, ok := fieldMap[b.returning[0]]
+		if !ok {
+			if key, hasKey := keyField(structFields(rv.Type())); hasKey {
+				f, ok = fieldMap[key.column(b.naming)]
+			}
+		}
+		if !ok {
+			return newError("builder", "missing field for returning column %s", b.returning[0])
+		}
+		rv = reflect.ValueOf(f).Elem()
+	}
+	idValue := reflect.ValueOf(id)
+	if !idValue.Type().ConvertibleTo(rv.Type()) {
+		return newError("builder", "can not set last insert id to %s", rv.Type())
+	}
+	rv.Set(idValue.Convert(rv.Type()))
+	return nil
+}
+
 func (b *Builder) Exec(ctx context.Context, db DB) (sql.Result, error) {
 	return db.Exec(ctx, b.Build())
 }
@@ -129,109 +327,266 @@ func (b *Builder) Dialect(d string) *Builder {
 	return b
 }
 
-func (b *Builder) Select(table string, columns ...string) *Builder {
-	b.action = actionSelect
-	b.table = NewTemplate(table)
+// Naming sets the column naming of the struct methods called after it.
+func (b *Builder) Naming(n Naming) *Builder {
+	b.naming = n
+	return b
+}
+
+// Select sets the table and columns of a select query, table is a name, a Template or a *Builder.
+func (b *Builder) Select(table interface{}, columns ...string) *Builder {
+	b.action = ActionSelect
+	b.table = toTemplate(table)
 	for _, c := range columns {
 		b.columns = append(b.columns, NewTemplate(c))
 	}
 	return b
 }
 
+// From sets the table of the query with an optional alias, it implies a select
+// if no action has been chosen yet. The table is a name, a Template or a *Builder.
+// For an update or delete, From adds a referenced table like Using.
+func (b *Builder) From(table interface{}, alias string) *Builder {
+	switch b.action {
+	case ActionUpdate, ActionDelete:
+		return b.Using(table, alias)
+	case "":
+		b.action = ActionSelect
+	}
+	b.table = toTemplate(table).As(alias)
+	return b
+}
+
+// Using adds a table that the where conditions of an update or delete refer to,
+// rendered as update from, delete using or a multi-table statement by the dialect.
+func (b *Builder) Using(table interface{}, alias string) *Builder {
+	b.using = append(b.using, toTemplate(table).As(alias))
+	return b
+}
+
+// Columns appends select columns, use Ident, Expr and Template.As to build them.
+func (b *Builder) Columns(columns ...Template) *Builder {
+	b.columns = append(b.columns, columns...)
+	return b
+}
+
 func (b *Builder) SelectStruct(table string, i interface{}, ignoreFields ...string) *Builder {
-	names := reflectutil.GetStructFieldNames(i)
-	b.action = actionSelect
+	b.action = ActionSelect
 	b.table = NewTemplate(table)
-	for _, name := range names {
-		if slices.ContainStrings(ignoreFields, name) {
+	cs := structColumns(reflect.TypeOf(i), b.naming)
+	for j, f := range cs.fields {
+		if slices.ContainStrings(ignoreFields, f.Name) {
 			continue
 		}
-		b.columns = append(b.columns, NewTemplate(name))
+		b.columns = append(b.columns, NewTemplate(cs.columns[j]))
 	}
 	return b
 }
 
 func (b *Builder) Insert(table string, columns map[string]interface{}) *Builder {
-	b.action = actionInsert
+	b.action = ActionInsert
 	b.table = NewTemplate(table)
-	for name, value := range columns {
-		b.columns = append(b.columns, NewTemplate(name, value))
+	for _, name := range sortedKeys(columns) {
+		b.columns = append(b.columns, NewTemplate(name, columns[name]))
 	}
 	return b
 }
 
+// InsertSelect inserts the rows of the select query into the columns of table.
+func (b *Builder) InsertSelect(table string, columns []string, query *Builder) *Builder {
+	b.action = ActionInsert
+	b.table = NewTemplate(table)
+	b.columns = NewPlainTemplates(columns...)
+	b.source = query
+	return b
+}
+
+// InsertStruct inserts the fields of i, skipping readonly fields, zero autoincr
+// and omitempty fields, and every zero field if ignoreZeroValue is set. Query
+// sets the autoincr or pk field of its struct from the inserted row.
 func (b *Builder) InsertStruct(table string, i interface{}, ignoreZeroValue bool, ignoreFields ...string) *Builder {
-	b.action = actionInsert
+	b.action = ActionInsert
 	b.table = NewTemplate(table)
-	m := reflectutil.ParseStructToMap(i)
-	for name, value := range m {
-		if ignoreZeroValue && reflect.ValueOf(value).IsZero() {
+	rv := reflectutil.DeepUnrefValue(reflect.ValueOf(i))
+	cs := structColumns(rv.Type(), b.naming)
+	if key, ok := keyField(cs.fields); ok {
+		b.key = key.column(b.naming)
+	}
+	for j, f := range cs.fields {
+		fv, ok := fieldByIndex(rv, f.Index)
+		if !ok {
+			continue
+		}
+		if f.Readonly || slices.ContainStrings(ignoreFields, f.Name) {
 			continue
 		}
-		if slices.ContainStrings(ignoreFields, name) {
+		if (ignoreZeroValue || f.OmitEmpty || f.AutoIncr) && isNullOrZero(fv) {
 			continue
 		}
-		b.columns = append(b.columns, NewTemplate(name, value))
+		b.columns = append(b.columns, NewTemplate(cs.columns[j], fieldInterface(fv)))
 	}
 	return b
 }
 
 func (b *Builder) Update(table string, columns map[string]interface{}) *Builder {
-	b.action = actionUpdate
+	b.action = ActionUpdate
 	b.table = NewTemplate(table)
-	for name, value := range columns {
-		b.columns = append(b.columns, NewTemplate(name, value))
+	for _, name := range sortedKeys(columns) {
+		b.columns = append(b.columns, NewTemplate(name, columns[name]))
 	}
 	return b
 }
 
+// UpdateStruct sets the fields of i, skipping readonly, pk and autoincr fields,
+// zero omitempty fields, and every zero field if ignoreZeroValue is set.
 func (b *Builder) UpdateStruct(table string, i interface{}, ignoreZeroValue bool, ignoreFields ...string) *Builder {
-	b.action = actionUpdate
+	b.action = ActionUpdate
 	b.table = NewTemplate(table)
-	m := reflectutil.ParseStructToMap(i)
-	for name, value := range m {
-		if ignoreZeroValue && reflect.ValueOf(value).IsZero() {
+	rv := reflectutil.DeepUnrefValue(reflect.ValueOf(i))
+	cs := structColumns(rv.Type(), b.naming)
+	for j, f := range cs.fields {
+		fv, ok := fieldByIndex(rv, f.Index)
+		if !ok {
+			continue
+		}
+		if f.Readonly || f.PK || f.AutoIncr || slices.ContainStrings(ignoreFields, f.Name) {
 			continue
 		}
-		if slices.ContainStrings(ignoreFields, name) {
+		if (ignoreZeroValue || f.OmitEmpty) && isNullOrZero(fv) {
 			continue
 		}
-		b.columns = append(b.columns, NewTemplate(name, value))
+		b.columns = append(b.columns, NewTemplate(cs.columns[j], fieldInterface(fv)))
 	}
 	return b
 }
 
 func (b *Builder) Delete(table string) *Builder {
-	b.action = actionDelete
+	b.action = ActionDelete
 	b.table = NewTemplate(table)
 	return b
 }
 
-func (b *Builder) Where(format string, values ...interface{}) *Builder {
-	b.where.Appendf(format, values...)
+// OnConflictDoNothing makes an insert skip rows that conflict on the columns,
+// which default to the key of the struct given to InsertStruct.
+func (b *Builder) OnConflictDoNothing(conflict ...string) *Builder {
+	b.upsert = &Upsert{Conflict: conflict}
 	return b
 }
 
-func (b *Builder) WhereIn(column string, values ...interface{}) *Builder {
-	if len(values) == 0 {
-		values = append(values, "null")
+// OnConflictDoUpdate makes an insert update the columns of the existing row
+// from the inserted one when it conflicts on the conflict columns.
+func (b *Builder) OnConflictDoUpdate(conflict []string, update ...string) *Builder {
+	b.upsert = &Upsert{Conflict: conflict, Update: update}
+	return b
+}
+
+// Returning makes an insert, update or delete return the columns of the
+// affected rows, use Query to bind them.
+func (b *Builder) Returning(columns ...string) *Builder {
+	b.returning = append(b.returning, columns...)
+	return b
+}
+
+// Join adds an inner join of table, on is a condition string with its values
+// or a condition template such as Eq, like the condition of Where.
+func (b *Builder) Join(table interface{}, alias string, on interface{}, values ...interface{}) *Builder {
+	return b.join("inner join", table, alias, toCondition(on, values...))
+}
+
+func (b *Builder) LeftJoin(table interface{}, alias string, on interface{}, values ...interface{}) *Builder {
+	return b.join("left join", table, alias, toCondition(on, values...))
+}
+
+func (b *Builder) RightJoin(table interface{}, alias string, on interface{}, values ...interface{}) *Builder {
+	return b.join("right join", table, alias, toCondition(on, values...))
+}
+
+func (b *Builder) FullJoin(table interface{}, alias string, on interface{}, values ...interface{}) *Builder {
+	return b.join("full join", table, alias, toCondition(on, values...))
+}
+
+func (b *Builder) CrossJoin(table interface{}, alias string) *Builder {
+	return b.join("cross join", table, alias, Template{})
+}
+
+func (b *Builder) join(kind string, table interface{}, alias string, on Template) *Builder {
+	b.joins = append(b.joins, join{kind: kind, table: toTemplate(table).As(alias), on: on})
+	return b
+}
+
+func (j join) build(d Dialect) Template {
+	t := NewTemplate(" " + j.kind + " ").Append(j.table.quote(d))
+	if j.on.Format != "" {
+		t = t.Appendf(" on ").Append(j.on)
 	}
-	holders := make([]string, len(values))
-	for i := 0; i < len(values); i++ {
-		holders = append(holders, "?")
+	return t
+}
+
+// With declares a common table expression emitted before the query.
+func (b *Builder) With(name string, query *Builder) *Builder {
+	b.ctes = append(b.ctes, cte{name: name, query: query})
+	return b
+}
+
+// WithRecursive declares a recursive common table expression, query usually
+// unions an anchor select with a select that joins name itself.
+func (b *Builder) WithRecursive(name string, columns []string, query *Builder) *Builder {
+	b.ctes = append(b.ctes, cte{name: name, columns: columns, query: query, recursive: true})
+	return b
+}
+
+// Union combines the select with others, OrderBy, Limit and Offset of b apply to the whole result.
+func (b *Builder) Union(others ...*Builder) *Builder {
+	return b.compound("union", others...)
+}
+
+func (b *Builder) UnionAll(others ...*Builder) *Builder {
+	return b.compound("union all", others...)
+}
+
+func (b *Builder) Intersect(others ...*Builder) *Builder {
+	return b.compound("intersect", others...)
+}
+
+func (b *Builder) Except(others ...*Builder) *Builder {
+	return b.compound("except", others...)
+}
+
+func (b *Builder) compound(op string, others ...*Builder) *Builder {
+	for _, o := range others {
+		b.compounds = append(b.compounds, compound{op: op, query: o})
 	}
-	format := fmt.Sprintf("%s in (%s)", GetDialect(b.dialect).Quote(column), strings.Join(holders, ", "))
-	b.where.Appendf(format, values...)
 	return b
 }
 
+// Where appends a condition, which is a format with its values or a Template
+// such as the ones built by Eq, And or Or.
+func (b *Builder) Where(condition interface{}, values ...interface{}) *Builder {
+	b.where = b.where.Append(toCondition(condition, values...))
+	return b
+}
+
+// WhereIn appends a column in condition, see In.
+func (b *Builder) WhereIn(column string, values ...interface{}) *Builder {
+	return b.Where(In(column, values...))
+}
+
 func (b *Builder) OrderBy(fields ...string) *Builder {
 	b.orderBy = append(b.orderBy, fields...)
 	return b
 }
 
 func (b *Builder) Paging(page, size int) *Builder {
-	b.paging = NewTemplate("limit ?,?", (page-1)*size, size)
+	return b.Limit(size).Offset((page - 1) * size)
+}
+
+func (b *Builder) Limit(n int) *Builder {
+	b.limit = n
+	return b
+}
+
+func (b *Builder) Offset(n int) *Builder {
+	b.offset = n
 	return b
 }
 
@@ -240,7 +595,7 @@ func (b *Builder) GroupBy(fields ...string) *Builder {
 	return b
 }
 
-func (b *Builder) Having(format string, values ...interface{}) *Builder {
-	b.having.Appendf(format, values...)
+func (b *Builder) Having(condition interface{}, values ...interface{}) *Builder {
+	b.having = b.having.Append(toCondition(condition, values...))
 	return b
 }
@@ -1,8 +1,7 @@
 package bear
 
 import (
-	"fmt"
-	"strings"
+	"reflect"
 
 	"github.com/medivhyang/duck/reflectutil"
 )
@@ -12,6 +11,9 @@ type BulkInsertBuilder struct {
 	table   string
 	columns []string
 	values  [][]interface{}
+	upsert  *Upsert
+	key     string
+	naming  Naming
 	err     error
 }
 
@@ -23,24 +25,20 @@ func (b *BulkInsertBuilder) Build() (Template, error) {
 	if b.err != nil {
 		return Template{}, b.err
 	}
+	d := GetDialect(b.dialect)
 
-	holders := make([]string, 0, len(b.values))
-	for i := 0; i < len(b.values); i++ {
-		holders = append(holders, "("+strings.Join(repeatString("?", len(b.columns)), ", ")+")")
+	u := Upsert{Table: b.table, Columns: b.columns, Rows: b.values}
+	if b.upsert == nil {
+		return u.Insert(d).Rebind(d), nil
 	}
-
-	flattenValues := make([]interface{}, 0, len(b.columns)*len(b.values))
-	for _, rowValues := range b.values {
-		flattenValues = append(flattenValues, rowValues...)
+	u.Conflict = b.upsert.Conflict
+	if len(u.Conflict) == 0 && b.key != "" {
+		u.Conflict = []string{b.key}
 	}
+	u.Update = b.upsert.Update
+	t := d.Upsert(u)
 
-	t := NewTemplate(fmt.Sprintf("insert into %s(%s) values%s",
-		b.table,
-		strings.Join(b.columns, ", "),
-		strings.Join(holders, ", "),
-	), flattenValues...)
-
-	return t, nil
+	return t.Rebind(d), nil
 }
 
 func (b *BulkInsertBuilder) Dialect(name string) *BulkInsertBuilder {
@@ -48,6 +46,12 @@ func (b *BulkInsertBuilder) Dialect(name string) *BulkInsertBuilder {
 	return b
 }
 
+// Naming sets the column naming of AppendStruct.
+func (b *BulkInsertBuilder) Naming(n Naming) *BulkInsertBuilder {
+	b.naming = n
+	return b
+}
+
 func (b *BulkInsertBuilder) Table(name string) *BulkInsertBuilder {
 	b.table = name
 	return b
@@ -58,6 +62,20 @@ func (b *BulkInsertBuilder) Columns(cc ...string) *BulkInsertBuilder {
 	return b
 }
 
+// OnConflictDoNothing skips rows that conflict on the columns, which default
+// to the key of the structs given to AppendStruct.
+func (b *BulkInsertBuilder) OnConflictDoNothing(conflict ...string) *BulkInsertBuilder {
+	b.upsert = &Upsert{Conflict: conflict}
+	return b
+}
+
+// OnConflictDoUpdate updates the columns of existing rows from the inserted
+// ones when they conflict on the conflict columns.
+func (b *BulkInsertBuilder) OnConflictDoUpdate(conflict []string, update ...string) *BulkInsertBuilder {
+	b.upsert = &Upsert{Conflict: conflict, Update: update}
+	return b
+}
+
 func (b *BulkInsertBuilder) Append(values ...[]interface{}) *BulkInsertBuilder {
 	b.values = append(b.values, values...)
 	return b
@@ -83,7 +101,22 @@ func (b *BulkInsertBuilder) AppendMap(mm ...map[string]interface{}) *BulkInsertB
 func (b *BulkInsertBuilder) AppendStruct(ii ...interface{}) *BulkInsertBuilder {
 	var mm []map[string]interface{}
 	for _, i := range ii {
-		m := reflectutil.ParseStructToMap(i)
+		rv := reflectutil.DeepUnrefValue(reflect.ValueOf(i))
+		m := map[string]interface{}{}
+		cs := structColumns(rv.Type(), b.naming)
+		if key, ok := keyField(cs.fields); ok {
+			b.key = key.column(b.naming)
+		}
+		for j, f := range cs.fields {
+			if f.Readonly {
+				continue
+			}
+			if fv, ok := fieldByIndex(rv, f.Index); ok {
+				m[cs.columns[j]] = fieldInterface(fv)
+			} else {
+				m[cs.columns[j]] = nil
+			}
+		}
 		mm = append(mm, m)
 	}
 	b.AppendMap(mm...)
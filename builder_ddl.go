@@ -2,6 +2,8 @@ package bear
 
 import (
 	"fmt"
+	"reflect"
+	"strconv"
 	"strings"
 )
 
@@ -10,6 +12,8 @@ type DDLBuilder struct {
 	dialect     string
 	table       string
 	columns     []Column
+	schema      reflect.Type
+	naming      Naming
 	checkExists bool
 	pretty      bool
 	prefix      string
@@ -56,6 +60,12 @@ func (b *DDLBuilder) Dialect(name string) *DDLBuilder {
 	return b
 }
 
+// Naming sets the column naming of CreateTableStruct.
+func (b *DDLBuilder) Naming(n Naming) *DDLBuilder {
+	b.naming = n
+	return b
+}
+
 func (b *DDLBuilder) Pretty(prefix string, indent string) *DDLBuilder {
 	b.pretty = true
 	b.prefix = prefix
@@ -72,6 +82,20 @@ func (b *DDLBuilder) CreateTable(table Table, checkExists bool) *DDLBuilder {
 	b.action = ddlActionCreateTable
 	b.table = table.Name
 	b.columns = table.Columns
+	b.schema = nil
+	b.checkExists = checkExists
+	return b
+}
+
+// CreateTableStruct creates a table whose columns are the fields of i, typed
+// by the dialect unless tagged with type=, and constrained by the pk, autoincr,
+// notnull, unique and default= tags, several pk fields making a composite primary
+// key. Fields sharing an index= tag are indexed together by BuildIndexes.
+func (b *DDLBuilder) CreateTableStruct(table string, i interface{}, checkExists bool) *DDLBuilder {
+	b.action = ddlActionCreateTable
+	b.table = table
+	b.columns = nil
+	b.schema = reflect.TypeOf(i)
 	b.checkExists = checkExists
 	return b
 }
@@ -84,25 +108,30 @@ func (b *DDLBuilder) DropTable(table string, checkExists bool) *DDLBuilder {
 }
 
 func (b *DDLBuilder) Build() Template {
+	d := GetDialect(b.dialect)
 	result := Template{}
 	buffer := strings.Builder{}
 	switch b.action {
 	case ddlActionCreateTable:
-		if len(b.columns) == 0 {
+		columns, constraints := b.columns, []string(nil)
+		if b.schema != nil {
+			columns, constraints = b.schemaColumns(d)
+		}
+		if len(columns) == 0 {
 			break
 		}
 		if b.checkExists {
-			buffer.WriteString(fmt.Sprintf("create table if not exists %s (", b.table))
+			buffer.WriteString(fmt.Sprintf("create table if not exists %s (", d.QuoteIdent(b.table)))
 		} else {
-			buffer.WriteString(fmt.Sprintf("create table %s (", b.table))
+			buffer.WriteString(fmt.Sprintf("create table %s (", d.QuoteIdent(b.table)))
 		}
 		if b.pretty {
 			buffer.WriteString("\n")
 		}
-		for _, column := range b.columns {
+		for _, column := range columns {
 			buffer.WriteString(b.indent)
 			buffer.WriteString(b.prefix)
-			buffer.WriteString(fmt.Sprintf("%s %s", column.Name, column.Type))
+			buffer.WriteString(fmt.Sprintf("%s %s", d.QuoteIdent(column.Name), column.Type))
 			suffix := strings.TrimSpace(column.Suffix)
 			if suffix != "" {
 				buffer.WriteString(" ")
@@ -113,6 +142,15 @@ func (b *DDLBuilder) Build() Template {
 				buffer.WriteString("\n")
 			}
 		}
+		for _, constraint := range constraints {
+			buffer.WriteString(b.indent)
+			buffer.WriteString(b.prefix)
+			buffer.WriteString(constraint)
+			buffer.WriteString(",")
+			if b.pretty {
+				buffer.WriteString("\n")
+			}
+		}
 		content := strings.TrimRight(buffer.String(), ", \n")
 		buffer.Reset()
 		buffer.WriteString(content)
@@ -125,9 +163,9 @@ func (b *DDLBuilder) Build() Template {
 		}
 	case ddlActionDropTable:
 		if b.checkExists {
-			buffer.WriteString(fmt.Sprintf("drop table if exists %s;", b.table))
+			buffer.WriteString(fmt.Sprintf("drop table if exists %s;", d.QuoteIdent(b.table)))
 		} else {
-			buffer.WriteString(fmt.Sprintf("drop table %s;", b.table))
+			buffer.WriteString(fmt.Sprintf("drop table %s;", d.QuoteIdent(b.table)))
 		}
 	}
 	result.Format = strings.TrimSpace(buffer.String())
@@ -136,3 +174,104 @@ func (b *DDLBuilder) Build() Template {
 	}
 	return result
 }
+
+// BuildIndexes returns a create index statement for every index= tag of the
+// CreateTableStruct fields, to run one by one after the create table statement.
+func (b *DDLBuilder) BuildIndexes() []Template {
+	if b.action != ddlActionCreateTable || b.schema == nil {
+		return nil
+	}
+	d := GetDialect(b.dialect)
+	var indexes []Index
+	cs := structColumns(b.schema, b.naming)
+	for k, f := range cs.fields {
+		if f.IndexName == "" {
+			continue
+		}
+		i := len(indexes)
+		for j, index := range indexes {
+			if index.Name == f.IndexName {
+				i = j
+			}
+		}
+		if i == len(indexes) {
+			indexes = append(indexes, Index{Name: f.IndexName, Table: b.table, CheckExists: b.checkExists})
+		}
+		indexes[i].Columns = append(indexes[i].Columns, cs.columns[k])
+	}
+	result := make([]Template, 0, len(indexes))
+	for _, index := range indexes {
+		result = append(result, NewTemplate(d.CreateIndex(index)))
+	}
+	return result
+}
+
+// schemaColumns returns the columns and table constraints of the schema struct.
+func (b *DDLBuilder) schemaColumns(d Dialect) ([]Column, []string) {
+	var (
+		columns     []Column
+		constraints []string
+		pk          []string
+	)
+	cs := structColumns(b.schema, b.naming)
+	for i, f := range cs.fields {
+		if f.PK {
+			pk = append(pk, d.QuoteIdent(cs.columns[i]))
+		}
+	}
+	if len(pk) > 1 {
+		constraints = append(constraints, fmt.Sprintf("primary key (%s)", strings.Join(pk, ", ")))
+	}
+	for i, f := range cs.fields {
+		name := cs.columns[i]
+		column := Column{Name: name, Type: f.SQLType}
+		if column.Type == "" {
+			column.Type = d.MappingType(nullableType(f.Type), f.Size)
+		}
+		var suffix []string
+		if f.NotNull {
+			suffix = append(suffix, "not null")
+		}
+		if f.Default != "" {
+			suffix = append(suffix, "default "+ddlDefault(d, f.Default))
+		}
+		if f.Unique {
+			suffix = append(suffix, "unique")
+		}
+		if f.PK && len(pk) == 1 {
+			suffix = append(suffix, "primary key")
+		}
+		if f.AutoIncr {
+			suffix = append(suffix, d.AutoIncrement())
+		}
+		column.Suffix = strings.Join(suffix, " ")
+		columns = append(columns, column)
+	}
+	return columns, constraints
+}
+
+// ddlDefault keeps numbers, booleans, null and function calls as is, and quotes other values as literals.
+func ddlDefault(d Dialect, s string) string {
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return s
+	}
+	switch strings.ToLower(s) {
+	case "true", "false", "null":
+		return s
+	}
+	if strings.Contains(s, "(") {
+		return s
+	}
+	return d.QuoteLiteral(s)
+}
+
+// nullableType returns the value type of the sql.Null* types such as
+// sql.NullString, whose first field holds the value and second field Valid.
+func nullableType(rt reflect.Type) reflect.Type {
+	t := derefType(rt)
+	if t.Kind() == reflect.Struct && t.NumField() == 2 && t.Field(1).Name == "Valid" &&
+		reflect.PtrTo(t).Implements(scannerType) {
+		return t.Field(0).Type
+	}
+	return rt
+}
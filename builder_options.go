@@ -8,12 +8,36 @@ func WithDialect(d string) BuilderOptionFunc {
 	}
 }
 
-func Select(table string, columns ...string) BuilderOptionFunc {
+func WithNaming(n Naming) BuilderOptionFunc {
+	return func(b *Builder) {
+		b.Naming(n)
+	}
+}
+
+func Select(table interface{}, columns ...string) BuilderOptionFunc {
 	return func(b *Builder) {
 		b.Select(table, columns...)
 	}
 }
 
+func From(table interface{}, alias string) BuilderOptionFunc {
+	return func(b *Builder) {
+		b.From(table, alias)
+	}
+}
+
+func Columns(columns ...Template) BuilderOptionFunc {
+	return func(b *Builder) {
+		b.Columns(columns...)
+	}
+}
+
+func Using(table interface{}, alias string) BuilderOptionFunc {
+	return func(b *Builder) {
+		b.Using(table, alias)
+	}
+}
+
 func SelectStruct(table string, i interface{}, ignoreColumns ...string) BuilderOptionFunc {
 	return func(b *Builder) {
 		b.SelectStruct(table, i, ignoreColumns...)
@@ -26,6 +50,30 @@ func Insert(table string, columns map[string]interface{}) BuilderOptionFunc {
 	}
 }
 
+func OnConflictDoNothing(conflict ...string) BuilderOptionFunc {
+	return func(b *Builder) {
+		b.OnConflictDoNothing(conflict...)
+	}
+}
+
+func OnConflictDoUpdate(conflict []string, update ...string) BuilderOptionFunc {
+	return func(b *Builder) {
+		b.OnConflictDoUpdate(conflict, update...)
+	}
+}
+
+func Returning(columns ...string) BuilderOptionFunc {
+	return func(b *Builder) {
+		b.Returning(columns...)
+	}
+}
+
+func InsertSelect(table string, columns []string, query *Builder) BuilderOptionFunc {
+	return func(b *Builder) {
+		b.InsertSelect(table, columns, query)
+	}
+}
+
 func Update(table string, columns map[string]interface{}) BuilderOptionFunc {
 	return func(b *Builder) {
 		b.Update(table, columns)
@@ -38,9 +86,75 @@ func Delete(table string) BuilderOptionFunc {
 	}
 }
 
-func Where(format string, values ...interface{}) BuilderOptionFunc {
+func Join(table interface{}, alias string, on interface{}, values ...interface{}) BuilderOptionFunc {
+	return func(b *Builder) {
+		b.Join(table, alias, on, values...)
+	}
+}
+
+func LeftJoin(table interface{}, alias string, on interface{}, values ...interface{}) BuilderOptionFunc {
+	return func(b *Builder) {
+		b.LeftJoin(table, alias, on, values...)
+	}
+}
+
+func RightJoin(table interface{}, alias string, on interface{}, values ...interface{}) BuilderOptionFunc {
+	return func(b *Builder) {
+		b.RightJoin(table, alias, on, values...)
+	}
+}
+
+func FullJoin(table interface{}, alias string, on interface{}, values ...interface{}) BuilderOptionFunc {
+	return func(b *Builder) {
+		b.FullJoin(table, alias, on, values...)
+	}
+}
+
+func CrossJoin(table interface{}, alias string) BuilderOptionFunc {
+	return func(b *Builder) {
+		b.CrossJoin(table, alias)
+	}
+}
+
+func With(name string, query *Builder) BuilderOptionFunc {
+	return func(b *Builder) {
+		b.With(name, query)
+	}
+}
+
+func WithRecursive(name string, columns []string, query *Builder) BuilderOptionFunc {
+	return func(b *Builder) {
+		b.WithRecursive(name, columns, query)
+	}
+}
+
+func Union(others ...*Builder) BuilderOptionFunc {
+	return func(b *Builder) {
+		b.Union(others...)
+	}
+}
+
+func UnionAll(others ...*Builder) BuilderOptionFunc {
+	return func(b *Builder) {
+		b.UnionAll(others...)
+	}
+}
+
+func Intersect(others ...*Builder) BuilderOptionFunc {
 	return func(b *Builder) {
-		b.Where(format, values...)
+		b.Intersect(others...)
+	}
+}
+
+func Except(others ...*Builder) BuilderOptionFunc {
+	return func(b *Builder) {
+		b.Except(others...)
+	}
+}
+
+func Where(condition interface{}, values ...interface{}) BuilderOptionFunc {
+	return func(b *Builder) {
+		b.Where(condition, values...)
 	}
 }
 
@@ -62,14 +176,26 @@ func Paging(page, size int) BuilderOptionFunc {
 	}
 }
 
+func Limit(n int) BuilderOptionFunc {
+	return func(b *Builder) {
+		b.Limit(n)
+	}
+}
+
+func Offset(n int) BuilderOptionFunc {
+	return func(b *Builder) {
+		b.Offset(n)
+	}
+}
+
 func GroupBy(fields ...string) BuilderOptionFunc {
 	return func(b *Builder) {
 		b.GroupBy(fields...)
 	}
 }
 
-func Having(format string, values ...interface{}) BuilderOptionFunc {
+func Having(condition interface{}, values ...interface{}) BuilderOptionFunc {
 	return func(b *Builder) {
-		b.Having(format, values...)
+		b.Having(condition, values...)
 	}
 }
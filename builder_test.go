@@ -0,0 +1,125 @@
+package bear_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/medivhyang/bear"
+	_ "github.com/medivhyang/bear/dialect/sqlite3"
+)
+
+type goldenUser struct {
+	ID      int64 `bear:"pk,autoincr"`
+	Name    string
+	Age     int
+	Email   string
+	Enabled bool
+}
+
+func assertTemplate(t *testing.T, build func() bear.Template, format string, values ...interface{}) {
+	t.Helper()
+	for i := 0; i < 20; i++ {
+		got := build()
+		if got.Format != format {
+			t.Fatalf("build %d: format\n got: %s\nwant: %s", i, got.Format, format)
+		}
+		if !reflect.DeepEqual(got.Values, values) {
+			t.Fatalf("build %d: values\n got: %#v\nwant: %#v", i, got.Values, values)
+		}
+	}
+}
+
+func TestBuilderInsertMap(t *testing.T) {
+	columns := map[string]interface{}{"name": "a", "age": 1, "email": "e", "enabled": true, "id": 9}
+	assertTemplate(t, func() bear.Template {
+		return bear.NewBuilder().Insert("user", columns).Build()
+	}, `insert into "user"("age","email","enabled","id","name") values(?,?,?,?,?)`, 1, "e", true, 9, "a")
+}
+
+func TestBuilderUpdateMap(t *testing.T) {
+	columns := map[string]interface{}{"name": "a", "age": 1, "email": "e", "enabled": true}
+	assertTemplate(t, func() bear.Template {
+		return bear.NewBuilder().Update("user", columns).Where("id = ?", 9).Build()
+	}, `update "user" set "age" = ?,"email" = ?,"enabled" = ?,"name" = ? where (id = ?)`, 1, "e", true, "a", 9)
+}
+
+func TestBuilderInsertStruct(t *testing.T) {
+	u := goldenUser{Name: "a", Age: 1, Email: "e", Enabled: true}
+	assertTemplate(t, func() bear.Template {
+		return bear.NewBuilder().InsertStruct("user", u, false).Build()
+	}, `insert into "user"("name","age","email","enabled") values(?,?,?,?)`, "a", 1, "e", true)
+}
+
+func TestBuilderUpdateStruct(t *testing.T) {
+	u := goldenUser{ID: 9, Name: "a", Age: 1, Email: "e", Enabled: true}
+	assertTemplate(t, func() bear.Template {
+		return bear.NewBuilder().UpdateStruct("user", u, false).Where("id = ?", u.ID).Build()
+	}, `update "user" set "name" = ?,"age" = ?,"email" = ?,"enabled" = ? where (id = ?)`, "a", 1, "e", true, int64(9))
+}
+
+func TestConditionsAppendMap(t *testing.T) {
+	m := map[string]interface{}{"name": "a", "age__gte": 18, "email__like": "%@x", "id__in": []int{1, 2}, "enabled": true}
+	assertTemplate(t, func() bear.Template {
+		return bear.NewBuilder().Select("user").Where(bear.NewConditions().AppendMap(m).JoinAnd()).Build()
+	}, `select * from "user" where (("age" >= ? and "email" like ? and "enabled" = ? and "id" in (?, ?) and "name" = ?))`, 18, "%@x", true, 1, 2, "a")
+}
+
+func TestConditionsAppendMapColumnKeys(t *testing.T) {
+	m := map[string]interface{}{"weird__col": 1, "deleted_at__isnull": "yes", "age__between": 1}
+	assertTemplate(t, func() bear.Template {
+		return bear.NewBuilder().Select("user").Where(bear.NewConditions().AppendMap(m).JoinAnd()).Build()
+	}, `select * from "user" where (("age__between" = ? and "deleted_at__isnull" = ? and "weird__col" = ?))`, 1, "yes", 1)
+}
+
+func TestConditionsAppendStruct(t *testing.T) {
+	u := goldenUser{Name: "a", Age: 1, Email: "e"}
+	assertTemplate(t, func() bear.Template {
+		return bear.NewBuilder().Select("user").Where(bear.NewConditions().AppendStruct(u, true).JoinAnd()).Build()
+	}, `select * from "user" where (("name" = ? and "age" = ? and "email" = ?))`, "a", 1, "e")
+}
+
+func TestConditionsAppendStructWith(t *testing.T) {
+	u := struct {
+		UserName  string
+		CreatedBy int
+	}{"a", 1}
+	assertTemplate(t, func() bear.Template {
+		return bear.NewBuilder().Select("user").Where(bear.NewConditions().AppendStructWith(u, true, bear.CamelNaming).JoinAnd()).Build()
+	}, `select * from "user" where (("userName" = ? and "createdBy" = ?))`, "a", 1)
+}
+
+func TestBuilderJoinCondition(t *testing.T) {
+	assertTemplate(t, func() bear.Template {
+		return bear.NewBuilder().From("post", "p").
+			LeftJoin("user", "u", bear.And(bear.Eq("u.id", bear.Ident("p.user_id")), bear.Gt("u.age", 18))).
+			Join("tag", "t", "t.post_id = p.id and t.name = ?", "go").
+			Build()
+	}, `select * from "post" as "p" left join "user" as "u" on ("u"."id" = "p"."user_id" and "u"."age" > ?) inner join "tag" as "t" on t.post_id = p.id and t.name = ?`, 18, "go")
+}
+
+func TestBuilderWithRecursive(t *testing.T) {
+	assertTemplate(t, func() bear.Template {
+		tree := bear.NewBuilder().Select("node", "id").Where("id = ?", 1).
+			UnionAll(bear.NewBuilder().Select("node", "node.id").Join("tree", "", "node.parent_id = tree.id"))
+		return bear.NewBuilder().WithRecursive("tree", []string{"id"}, tree).Select("tree").Build()
+	}, `with recursive "tree"("id") as (select "id" from "node" where (id = ?) union all select "node"."id" from "node" inner join "tree" on node.parent_id = tree.id) select * from "tree"`, 1)
+}
+
+func TestBuilderWhereIn(t *testing.T) {
+	assertTemplate(t, func() bear.Template {
+		return bear.NewBuilder().Select("user").WhereIn("id", 1, 2).WhereIn("name").Build()
+	}, `select * from "user" where ("id" in (?, ?) and 1 = 0)`, 1, 2)
+}
+
+func TestBuilderNamingClosures(t *testing.T) {
+	prefix := func(p string) bear.Naming {
+		return func(field string) string { return p + bear.SnakeNaming(field) }
+	}
+	u := goldenUser{ID: 1, Name: "a"}
+	assertTemplate(t, func() bear.Template {
+		return bear.NewBuilder().Naming(prefix("a_")).InsertStruct("user", u, true).Build()
+	}, `insert into "user"("a_id","a_name") values(?,?)`, int64(1), "a")
+	assertTemplate(t, func() bear.Template {
+		return bear.NewBuilder().Naming(prefix("b_")).InsertStruct("user", u, true).Build()
+	}, `insert into "user"("b_id","b_name") values(?,?)`, int64(1), "a")
+}
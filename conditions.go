@@ -1,13 +1,19 @@
 package bear
 
 import (
-	"fmt"
 	"reflect"
 	"strings"
 
 	"github.com/medivhyang/duck/reflectutil"
+	"github.com/medivhyang/duck/slices"
 )
 
+const FilterSep = "__"
+
+// Conditions are the conditions of a where or having clause. Conditions built
+// by Eq, In, AppendMap and the like keep their column as an Ident value, so a
+// joined template is not plain sql until it is rendered by a Builder or a DB,
+// or by Expand with a dialect.
 type Conditions []Template
 
 func NewConditions(tt ...Template) Conditions {
@@ -22,35 +28,67 @@ func (cc Conditions) Appendf(format string, values ...interface{}) Conditions {
 	return cc.Append(NewTemplate(format, values...))
 }
 
+// AppendMap appends a condition for every key of m, see AppendFilter for the
+// key syntax. A key that is not a valid filter, such as one with an unknown
+// operator suffix, is a column compared for equality as a whole, use
+// AppendFilter to reject such keys.
 func (cc Conditions) AppendMap(m map[string]interface{}) Conditions {
-	t := NewTemplate("")
-	for k, v := range m {
-		t = t.Appendf(fmt.Sprintf("%s = ?", k), v)
+	for _, k := range sortedKeys(m) {
+		t, err := parseFilter(k, m[k])
+		if err != nil {
+			t = Eq(k, m[k])
+		}
+		cc = cc.Append(t)
 	}
-	return cc.Append(t)
+	return cc
+}
+
+// AppendFilter appends a condition for every key of m, which is a column with an
+// optional operator suffix such as "age__gte", "name__like", "status__in" or
+// "deleted_at__isnull". Keys whose column is not in allowed are rejected.
+func (cc Conditions) AppendFilter(m map[string]interface{}, allowed []string) (Conditions, error) {
+	for _, k := range sortedKeys(m) {
+		column, _ := splitFilterKey(k)
+		if !slices.ContainStrings(allowed, column) {
+			return cc, newError("conditions", "column %s is not allowed", column)
+		}
+		t, err := parseFilter(k, m[k])
+		if err != nil {
+			return cc, err
+		}
+		cc = cc.Append(t)
+	}
+	return cc, nil
 }
 
 func (cc Conditions) AppendStruct(i interface{}, ignoreZeroValue bool) Conditions {
-	m := reflectutil.ParseStructToMap(i)
-	m2 := make(map[string]interface{}, len(m))
-	for k, v := range m {
-		if ignoreZeroValue && reflect.ValueOf(v).IsZero() {
+	return cc.AppendStructWith(i, ignoreZeroValue, nil)
+}
+
+// AppendStructWith appends an equality condition for the fields of i like
+// AppendStruct, with columns named by n instead of the default naming.
+func (cc Conditions) AppendStructWith(i interface{}, ignoreZeroValue bool, n Naming) Conditions {
+	rv := reflectutil.DeepUnrefValue(reflect.ValueOf(i))
+	cs := structColumns(rv.Type(), n)
+	for i, f := range cs.fields {
+		fv, ok := fieldByIndex(rv, f.Index)
+		if !ok {
 			continue
 		}
-		m2[k] = v
+		if (ignoreZeroValue || f.OmitEmpty) && isNullOrZero(fv) {
+			continue
+		}
+		cc = cc.Append(Eq(cs.columns[i], fieldInterface(fv)))
 	}
-	return cc.AppendMap(m2)
+	return cc
 }
 
 func (cc Conditions) AppendIn(column string, values ...interface{}) Conditions {
-	if len(values) == 0 {
-		values = append(values, "null")
-	}
-	holders := repeatString("?", len(values))
-	format := fmt.Sprintf("%s in (%s)", GetDefaultDialect().Quote(column), strings.Join(holders, ", "))
-	return cc.Appendf(format, values...)
+	return cc.Append(In(column, values...))
 }
 
+// Join joins the conditions with sep between right and left, keeping their
+// values, see Template.Expand to render the result as plain sql.
 func (cc Conditions) Join(sep string, right, left string) Template {
 	if len(cc) == 0 {
 		return Template{}
@@ -71,3 +109,162 @@ func (cc Conditions) JoinAnd() Template {
 func (cc Conditions) JoinOr() Template {
 	return cc.Join(" or ", "(", ")")
 }
+
+func splitFilterKey(key string) (column string, op string) {
+	i := strings.LastIndex(key, FilterSep)
+	if i < 0 {
+		return key, "eq"
+	}
+	return key[:i], key[i+len(FilterSep):]
+}
+
+func parseFilter(key string, value interface{}) (Template, error) {
+	column, op := splitFilterKey(key)
+	switch op {
+	case "eq":
+		return Eq(column, value), nil
+	case "ne":
+		return Ne(column, value), nil
+	case "gt":
+		return Gt(column, value), nil
+	case "gte":
+		return Gte(column, value), nil
+	case "lt":
+		return Lt(column, value), nil
+	case "lte":
+		return Lte(column, value), nil
+	case "like":
+		return Like(column, value), nil
+	case "ilike":
+		return ILike(column, value), nil
+	case "in":
+		return In(column, filterValues(value)...), nil
+	case "notin":
+		return NotIn(column, filterValues(value)...), nil
+	case "between":
+		values := filterValues(value)
+		if len(values) != 2 {
+			return Template{}, newError("conditions", "%s requires 2 values", key)
+		}
+		return Between(column, values[0], values[1]), nil
+	case "isnull":
+		null, ok := value.(bool)
+		if !ok {
+			return Template{}, newError("conditions", "%s requires a bool value", key)
+		}
+		if null {
+			return IsNull(column), nil
+		}
+		return IsNotNull(column), nil
+	default:
+		return Template{}, newError("conditions", "unknown operator %s", op)
+	}
+}
+
+func filterValues(value interface{}) []interface{} {
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			break
+		}
+		return reflectutil.ToSlice(value)
+	}
+	return []interface{}{value}
+}
+
+func toCondition(i interface{}, values ...interface{}) Template {
+	switch v := i.(type) {
+	case string:
+		return NewTemplate(v, values...)
+	case Template:
+		return v.AppendValues(values...)
+	default:
+		panic(newError("condition", "unsupported type %T", i))
+	}
+}
+
+func compare(column string, op string, value interface{}) Template {
+	return NewTemplate("? "+op+" ?", Ident(column), value)
+}
+
+func Eq(column string, value interface{}) Template {
+	return compare(column, "=", value)
+}
+
+func Ne(column string, value interface{}) Template {
+	return compare(column, "<>", value)
+}
+
+func Gt(column string, value interface{}) Template {
+	return compare(column, ">", value)
+}
+
+func Gte(column string, value interface{}) Template {
+	return compare(column, ">=", value)
+}
+
+func Lt(column string, value interface{}) Template {
+	return compare(column, "<", value)
+}
+
+func Lte(column string, value interface{}) Template {
+	return compare(column, "<=", value)
+}
+
+func Like(column string, pattern interface{}) Template {
+	return compare(column, "like", pattern)
+}
+
+// ILike matches case insensitively by lowering both sides, which every dialect supports.
+func ILike(column string, pattern interface{}) Template {
+	return NewTemplate("lower(?) like lower(?)", Ident(column), pattern)
+}
+
+func Between(column string, from interface{}, to interface{}) Template {
+	return NewTemplate("? between ? and ?", Ident(column), from, to)
+}
+
+func IsNull(column string) Template {
+	return NewTemplate("? is null", Ident(column))
+}
+
+func IsNotNull(column string) Template {
+	return NewTemplate("? is not null", Ident(column))
+}
+
+// In matches the column against values, or against the rows of a single *Builder value.
+func In(column string, values ...interface{}) Template {
+	return in(column, "in", values...)
+}
+
+func NotIn(column string, values ...interface{}) Template {
+	return in(column, "not in", values...)
+}
+
+// in renders an empty value list as always false for in and always true for not in.
+func in(column string, op string, values ...interface{}) Template {
+	if len(values) == 0 {
+		if op == "in" {
+			return Expr("1 = 0")
+		}
+		return Expr("1 = 1")
+	}
+	holders := "(" + strings.Join(repeatString("?", len(values)), ", ") + ")"
+	if _, ok := values[0].(*Builder); ok && len(values) == 1 {
+		holders = "?"
+	}
+	return NewTemplate("? "+op+" "+holders, append([]interface{}{Ident(column)}, values...)...)
+}
+
+func And(conditions ...Template) Template {
+	return Conditions(conditions).JoinAnd()
+}
+
+func Or(conditions ...Template) Template {
+	return Conditions(conditions).JoinOr()
+}
+
+func Not(condition Template) Template {
+	return NewTemplate("not (?)", condition)
+}
@@ -0,0 +1,127 @@
+package bear
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/medivhyang/duck/reflectutil"
+)
+
+// Cursor reads rows one at a time, so results of any size are processed in constant memory.
+type Cursor struct {
+	rows   *Rows
+	rt     reflect.Type
+	fields []*field
+	err    error
+}
+
+// Cursor returns a cursor over the rows.
+func (r *Rows) Cursor() *Cursor {
+	return &Cursor{rows: r}
+}
+
+func (c *Cursor) Next() bool {
+	if c.err != nil {
+		return false
+	}
+	return c.rows.Raw.Next()
+}
+
+// Scan scans the current row into dest, a pointer to a struct, to a
+// map[string]interface{} or to scalars. The column mapping of a struct type
+// is computed on its first row.
+func (c *Cursor) Scan(dest ...interface{}) error {
+	if len(dest) != 1 {
+		return c.rows.Raw.Scan(dest...)
+	}
+	rv := reflect.ValueOf(dest[0])
+	if rv.Kind() != reflect.Ptr {
+		return newError("cursor", "require pointer type")
+	}
+	rv = reflectutil.DeepUnrefAndNewValue(rv)
+	switch {
+	case rv.Type() == reflect.TypeOf(map[string]interface{}{}):
+		m, err := c.scanMap()
+		if err != nil {
+			return err
+		}
+		rv.Set(reflect.ValueOf(m))
+		return nil
+	case isNestedStruct(rv.Type()):
+		if c.rt != rv.Type() {
+			cc, err := c.rows.Raw.Columns()
+			if err != nil {
+				return err
+			}
+			ff, err := c.rows.columnFields(cc, rv.Type())
+			if err != nil {
+				c.err = err
+				return err
+			}
+			c.rt, c.fields = rv.Type(), ff
+		}
+		return c.rows.scanStruct(rv, c.fields)
+	default:
+		return c.rows.Raw.Scan(dest...)
+	}
+}
+
+func (c *Cursor) scanMap() (map[string]interface{}, error) {
+	columns, err := c.rows.Raw.Columns()
+	if err != nil {
+		return nil, err
+	}
+	values := make([]interface{}, len(columns))
+	for i := range values {
+		var v interface{}
+		values[i] = &v
+	}
+	if err := c.rows.Raw.Scan(values...); err != nil {
+		return nil, err
+	}
+	item := make(map[string]interface{}, len(columns))
+	for i, v := range values {
+		item[columns[i]] = *v.(*interface{})
+	}
+	return item, nil
+}
+
+// Err returns the error that stopped Next.
+func (c *Cursor) Err() error {
+	if c.err != nil {
+		return c.err
+	}
+	return c.rows.Raw.Err()
+}
+
+func (c *Cursor) Close() error {
+	return c.rows.Raw.Close()
+}
+
+// Each scans the rows of the query into values of T one at a time and calls fn
+// for each, until fn returns an error or calls abort.
+func Each[T any](ctx context.Context, db DB, t Template, fn func(item T, abort func()) error) error {
+	c, err := db.Iterate(ctx, t)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	abort := false
+	abortFunc := func() { abort = true }
+	for c.Next() {
+		var item T
+		if err := c.Scan(&item); err != nil {
+			return err
+		}
+		if err := fn(item, abortFunc); err != nil {
+			return err
+		}
+		if abort {
+			break
+		}
+	}
+	if err := c.Err(); err != nil {
+		return err
+	}
+	return c.Close()
+}
@@ -12,6 +12,7 @@ type Raw interface {
 
 type DB interface {
 	Query(ctx context.Context, t Template, i interface{}) error
+	Iterate(ctx context.Context, t Template) (*Cursor, error)
 	Exec(ctx context.Context, t Template) (sql.Result, error)
 	Tx(ctx context.Context, fn func(ctx context.Context, tx DB) error) (err error)
 	BeginTx(ctx context.Context) (DB, error)
@@ -20,14 +21,49 @@ type DB interface {
 }
 
 type db struct {
-	raw Raw
+	raw     Raw
+	dialect string
+	naming  Naming
+	strict  bool
 }
 
-func NewDB(r Raw) DB {
-	return &db{raw: r}
+type DBOptionFunc func(db *db)
+
+// WithDBDialect sets the dialect used to expand templates whose values are
+// templates, such as conditions made of Eq, the default dialect if not set.
+func WithDBDialect(name string) DBOptionFunc {
+	return func(db *db) {
+		db.dialect = name
+	}
+}
+
+// WithDBNaming sets the column naming used to bind query results into structs.
+func WithDBNaming(n Naming) DBOptionFunc {
+	return func(db *db) {
+		db.naming = n
+	}
 }
 
-func OpenDB(driverName string, dataSourceName string) (DB, error) {
+// WithDBStrict makes query results fail to bind into structs whose fields and
+// columns do not match, see Rows.Strict.
+func WithDBStrict(strict bool) DBOptionFunc {
+	return func(db *db) {
+		db.strict = strict
+	}
+}
+
+func NewDB(r Raw, options ...DBOptionFunc) DB {
+	db := &db{raw: r}
+	for _, option := range options {
+		if option == nil {
+			continue
+		}
+		option(db)
+	}
+	return db
+}
+
+func OpenDB(driverName string, dataSourceName string, options ...DBOptionFunc) (DB, error) {
 	r, err := sql.Open(driverName, dataSourceName)
 	if err != nil {
 		return nil, err
@@ -35,19 +71,42 @@ func OpenDB(driverName string, dataSourceName string) (DB, error) {
 	if err := r.Ping(); err != nil {
 		return nil, err
 	}
-	return NewDB(r), err
+	return NewDB(r, options...), err
 }
 
 func (db *db) Query(ctx context.Context, t Template, i interface{}) error {
+	t = db.expand(t)
 	debugf("query: %s", t.String())
 	rows, err := db.raw.QueryContext(ctx, t.Format, t.Values...)
 	if err != nil {
 		return err
 	}
-	return NewRows(rows).Bind(i)
+	return NewRows(rows).Naming(db.naming).Strict(db.strict).Bind(i)
+}
+
+// Iterate runs the query and returns a cursor over its rows, which must be closed.
+func (db *db) Iterate(ctx context.Context, t Template) (*Cursor, error) {
+	t = db.expand(t)
+	debugf("iterate: %s", t.String())
+	rows, err := db.raw.QueryContext(ctx, t.Format, t.Values...)
+	if err != nil {
+		return nil, err
+	}
+	return NewRows(rows).Naming(db.naming).Strict(db.strict).Cursor(), nil
+}
+
+// expand renders the templates and builders in the values of t with the
+// dialect of db and rebinds its placeholders, see Template.Expand.
+func (db *db) expand(t Template) Template {
+	d := LookupDialect(db.dialect)
+	if d == nil {
+		return t
+	}
+	return t.Expand(d)
 }
 
 func (db *db) Exec(ctx context.Context, t Template) (sql.Result, error) {
+	t = db.expand(t)
 	debugf("exec: %s", t.String())
 	return db.raw.ExecContext(ctx, t.Format, t.Values...)
 }
@@ -72,7 +131,7 @@ func (db *db) Tx(ctx context.Context, fn func(ctx context.Context, tx DB) error)
 func (db *db) BeginTx(ctx context.Context) (DB, error) {
 	tx, ok := db.raw.(*sql.Tx)
 	if ok {
-		return NewDB(tx), nil
+		return NewDB(tx, WithDBDialect(db.dialect), WithDBNaming(db.naming), WithDBStrict(db.strict)), nil
 	}
 	r, ok := db.raw.(*sql.DB)
 	if ok {
@@ -82,7 +141,7 @@ func (db *db) BeginTx(ctx context.Context) (DB, error) {
 			return nil, err
 		}
 		debugf("tx", "begin tx success")
-		return NewDB(tx), nil
+		return NewDB(tx, WithDBDialect(db.dialect), WithDBNaming(db.naming), WithDBStrict(db.strict)), nil
 	}
 	return nil, newError("tx", "invalid db type")
 }
@@ -0,0 +1,111 @@
+package bear_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/medivhyang/bear"
+	"github.com/medivhyang/bear/dialect/postgres"
+	_ "github.com/medivhyang/bear/dialect/sqlite3"
+)
+
+func TestDBRebindsPlainTemplates(t *testing.T) {
+	raw, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer raw.Close()
+	var out bytes.Buffer
+	bear.Debug(true)
+	bear.Output(&out)
+	defer bear.Output(os.Stdout)
+	defer bear.Debug(false)
+
+	db := bear.NewDB(raw, bear.WithDBDialect(postgres.Name))
+	if _, err := db.Exec(context.Background(), bear.NewTemplate("select ? + ?", 1, 2)); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), `"select $1 + $2"`) {
+		t.Fatalf("want rebound placeholders, got %s", out.String())
+	}
+}
+
+// nullCode stores "" as null, only its pointer implements driver.Valuer.
+type nullCode string
+
+func (c *nullCode) Value() (driver.Value, error) {
+	if *c == "" {
+		return nil, nil
+	}
+	return strings.ToUpper(string(*c)), nil
+}
+
+func (c *nullCode) Scan(v interface{}) error {
+	switch v := v.(type) {
+	case nil:
+		*c = ""
+	case string:
+		*c = nullCode(v)
+	case []byte:
+		*c = nullCode(v)
+	default:
+		return fmt.Errorf("unsupported code %T", v)
+	}
+	return nil
+}
+
+type nullUser struct {
+	ID    int64 `bear:"pk,autoincr"`
+	Score sql.NullInt64
+	Nick  *string
+	Code  nullCode
+}
+
+func TestDBNullAndZeroFields(t *testing.T) {
+	raw, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw.SetMaxOpenConns(1)
+	defer raw.Close()
+	db, ctx := bear.NewDB(raw), context.Background()
+	if _, err := db.Exec(ctx, bear.NewDDLBuilder().CreateTableStruct("null_user", nullUser{}, false).Build()); err != nil {
+		t.Fatal(err)
+	}
+
+	// a valid zero and a pointer to zero are not zero, a null valuer is.
+	empty := ""
+	zero := bear.NewBuilder().InsertStruct("null_user", nullUser{Score: sql.NullInt64{Valid: true}, Nick: &empty}, true)
+	if got := zero.Build().Format; got != `insert into "null_user"("score","nick") values(?,?)` {
+		t.Fatalf("zero values: got %s", got)
+	}
+	null := bear.NewBuilder().InsertStruct("null_user", nullUser{Code: "ab"}, true)
+	if got := null.Build().Format; got != `insert into "null_user"("code") values(?)` {
+		t.Fatalf("null values: got %s", got)
+	}
+	for _, b := range []*bear.Builder{zero, null} {
+		if _, err := b.Exec(ctx, db); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	users, err := bear.QueryAll[nullUser](ctx, db, bear.NewBuilder().Select("null_user").OrderBy("id"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 2 {
+		t.Fatalf("got %d users, want 2", len(users))
+	}
+	if u := users[0]; !u.Score.Valid || u.Score.Int64 != 0 || u.Nick == nil || *u.Nick != "" || u.Code != "" {
+		t.Fatalf("zero values: got %+v", u)
+	}
+	if u := users[1]; u.Score.Valid || u.Nick != nil || u.Code != "AB" {
+		t.Fatalf("null values: got %+v", u)
+	}
+}
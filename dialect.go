@@ -1,7 +1,9 @@
 package bear
 
 import (
+	"fmt"
 	"reflect"
+	"strings"
 	"sync"
 )
 
@@ -11,8 +13,129 @@ var (
 )
 
 type Dialect interface {
-	MappingType(rt reflect.Type) string
-	Quote(s string) string
+	// MappingType returns the column type of rt, size is the length of
+	// strings if positive.
+	MappingType(rt reflect.Type, size int) string
+	// AutoIncrement returns the column constraint of an auto increment key.
+	AutoIncrement() string
+	// QuoteIdent quotes a table or column name, see QuoteIdentWith.
+	QuoteIdent(s string) string
+	// QuoteLiteral quotes s as a string literal.
+	QuoteLiteral(s string) string
+	// Placeholder returns the bind parameter for the n-th value, counting from 1.
+	Placeholder(n int) string
+	// Paging limits the rows of the select query t to the page p, either by
+	// appending a clause or by rewriting t as a whole.
+	Paging(t Template, p Page) Template
+	// With returns the keyword starting a list of common table expressions,
+	// recursive tells whether one of them refers to itself.
+	With(recursive bool) string
+	// Upsert renders the insert statement described by u.
+	Upsert(u Upsert) Template
+	// Returning renders the clauses that make an insert, update or delete
+	// return the columns of the affected rows: output is placed before the
+	// values, select, from or where part of the statement and returning at
+	// its end. Both are "" if the dialect cannot return rows.
+	Returning(action Action, columns []string) (output string, returning string)
+	// UpdateFrom renders an update that references other tables.
+	UpdateFrom(m MultiTable) Template
+	// DeleteUsing renders a delete that references other tables.
+	DeleteUsing(m MultiTable) Template
+	// CreateIndex renders the statement creating the index i.
+	CreateIndex(i Index) string
+}
+
+// Index describes an index of the Columns of Table. CheckExists skips
+// creating it if it exists, where the dialect supports it.
+type Index struct {
+	Name        string
+	Table       string
+	Columns     []string
+	CheckExists bool
+}
+
+// QuoteIdents quotes every name of ss with d.
+func QuoteIdents(d Dialect, ss []string) []string {
+	r := make([]string, 0, len(ss))
+	for _, s := range ss {
+		r = append(r, d.QuoteIdent(s))
+	}
+	return r
+}
+
+// Page describes the rows kept of a select query. A non-positive Limit or
+// Offset is not rendered. Ordered tells whether the query has an order by
+// clause and Compound whether it has a union, intersect or except part.
+type Page struct {
+	Limit    int
+	Offset   int
+	Ordered  bool
+	Compound bool
+}
+
+// MultiTable describes an update or delete of Table whose Where condition
+// references the Others tables. Table and Others are quoted with their aliases,
+// Target is the quoted name or alias that refers to Table, Set is the quoted
+// assignment list of an update, of the Columns in order, and Output is the
+// output clause from Returning.
+type MultiTable struct {
+	Table   Template
+	Target  string
+	Set     Template
+	Columns []string
+	Others  Templates
+	Where   Template
+	Output  string
+}
+
+// OutputClause returns the Output of m preceded by a space, or "" if empty.
+func (m MultiTable) OutputClause() string {
+	return clause(m.Output)
+}
+
+// AppendWhere appends the where clause of m to t, if any.
+func (m MultiTable) AppendWhere(t Template) Template {
+	if m.Where.Format == "" {
+		return t
+	}
+	return t.Appendf(" where ").Append(m.Where)
+}
+
+// Upsert describes an insert of rows that resolves conflicts on the Conflict
+// columns by updating the Update columns from the inserted row, or by skipping
+// the row if Update is empty. Conflict is empty if neither the caller nor an
+// inserted struct names a key. Output is the output clause from Returning.
+type Upsert struct {
+	Table    string
+	Columns  []string
+	Rows     [][]interface{}
+	Conflict []string
+	Update   []string
+	Output   string
+}
+
+// OutputClause returns the Output of u preceded by a space, or "" if empty.
+func (u Upsert) OutputClause() string {
+	return clause(u.Output)
+}
+
+// Insert renders the plain insert statement of u, for dialects to append their conflict clause.
+func (u Upsert) Insert(d Dialect) Template {
+	columns := make([]string, 0, len(u.Columns))
+	for _, c := range u.Columns {
+		columns = append(columns, d.QuoteIdent(c))
+	}
+	holders := make([]string, 0, len(u.Rows))
+	values := make([]interface{}, 0, len(u.Columns)*len(u.Rows))
+	for _, row := range u.Rows {
+		holders = append(holders, "("+strings.Join(repeatString("?", len(row)), ", ")+")")
+		values = append(values, row...)
+	}
+	return NewTemplate(fmt.Sprintf("insert into %s(%s) values%s",
+		d.QuoteIdent(u.Table),
+		strings.Join(columns, ", "),
+		strings.Join(holders, ", "),
+	), values...)
 }
 
 var dialects sync.Map
@@ -63,3 +186,23 @@ func LookupDialect(name string) Dialect {
 	}
 	return d
 }
+
+const identExprChars = " \t\r\n()+-*/,'?:=<>|!%&;"
+
+// QuoteIdentWith wraps every part of the dotted name s between left and right,
+// doubling any right quote inside a part. "*" parts and parts that are already
+// quoted are kept, and s is returned unchanged if it looks like an expression.
+func QuoteIdentWith(s string, left string, right string) string {
+	parts := strings.Split(s, ".")
+	for i, part := range parts {
+		switch {
+		case part == "*":
+		case len(part) >= len(left)+len(right) && strings.HasPrefix(part, left) && strings.HasSuffix(part, right):
+		case part == "" || strings.ContainsAny(part, identExprChars):
+			return s
+		default:
+			parts[i] = left + strings.ReplaceAll(part, right, right+right) + right
+		}
+	}
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,195 @@
+package mssql
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	_ "github.com/denisenkom/go-mssqldb"
+	"github.com/medivhyang/bear"
+)
+
+const Name = "mssql"
+
+func init() {
+	bear.RegisterDialect(Name, &Dialect{})
+}
+
+const DefaultNVarcharSize = 255
+
+type Dialect struct {
+	// NVarcharSize is the length used for string columns, DefaultNVarcharSize if zero.
+	NVarcharSize int
+}
+
+func (d *Dialect) MappingType(rt reflect.Type, size int) string {
+	if rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	switch rt.Kind() {
+	case reflect.Bool:
+		return "bit"
+	case reflect.Uint8:
+		return "tinyint"
+	case reflect.Int8, reflect.Int16:
+		return "smallint"
+	case reflect.Int32, reflect.Uint16:
+		return "int"
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32:
+		return "bigint"
+	case reflect.Uint64:
+		return "decimal(20)"
+	case reflect.Float32:
+		return "real"
+	case reflect.Float64:
+		return "float"
+	case reflect.String:
+		if size <= 0 {
+			size = d.NVarcharSize
+		}
+		if size <= 0 {
+			size = DefaultNVarcharSize
+		}
+		return fmt.Sprintf("nvarchar(%d)", size)
+	case reflect.Slice:
+		if rt.Elem().Kind() == reflect.Uint8 {
+			return "varbinary(max)"
+		}
+		return ""
+	case reflect.Map:
+		return "nvarchar(max)"
+	default:
+		switch rt.String() {
+		case "time.Time":
+			return "datetime2"
+		}
+		return ""
+	}
+}
+
+func (d *Dialect) AutoIncrement() string {
+	return "identity(1,1)"
+}
+
+func (d *Dialect) QuoteIdent(s string) string {
+	return bear.QuoteIdentWith(s, "[", "]")
+}
+
+func (d *Dialect) QuoteLiteral(s string) string {
+	return "N'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+func (d *Dialect) Placeholder(n int) string {
+	return "@p" + strconv.Itoa(n)
+}
+
+// Paging renders "top (?)" for the first page of a simple unordered select,
+// otherwise offset/fetch, which sql server only accepts after an order by clause.
+func (d *Dialect) Paging(t bear.Template, p bear.Page) bear.Template {
+	limit, offset := p.Limit, p.Offset
+	if offset <= 0 && !p.Ordered && !p.Compound {
+		for _, prefix := range []string{"select distinct ", "select "} {
+			if strings.HasPrefix(t.Format, prefix) {
+				return bear.NewTemplate(prefix+"top (?) ", limit).Appendf(t.Format[len(prefix):], t.Values...)
+			}
+		}
+	}
+	switch {
+	case p.Ordered:
+	case p.Compound:
+		t = t.Appendf(" order by 1")
+	default:
+		t = t.Appendf(" order by (select null)")
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	t = t.Appendf(" offset ? rows", offset)
+	if limit > 0 {
+		t = t.Appendf(" fetch next ? rows only", limit)
+	}
+	return t
+}
+
+// With omits recursive, sql server allows a common table expression to refer
+// to itself without it.
+func (d *Dialect) With(recursive bool) string {
+	return "with"
+}
+
+// Upsert renders a merge statement matching the rows with the table on the
+// conflict columns, it panics without any as merge requires a match condition.
+func (d *Dialect) Upsert(u bear.Upsert) bear.Template {
+	if len(u.Conflict) == 0 {
+		panic("bear: mssql: upsert: require conflict columns")
+	}
+	columns := make([]string, 0, len(u.Columns))
+	sources := make([]string, 0, len(u.Columns))
+	for _, c := range u.Columns {
+		columns = append(columns, d.QuoteIdent(c))
+		sources = append(sources, "[source]."+d.QuoteIdent(c))
+	}
+	holders := make([]string, 0, len(u.Rows))
+	values := make([]interface{}, 0, len(u.Columns)*len(u.Rows))
+	for _, row := range u.Rows {
+		holders = append(holders, "("+strings.TrimSuffix(strings.Repeat("?, ", len(row)), ", ")+")")
+		values = append(values, row...)
+	}
+	on := make([]string, 0, len(u.Conflict))
+	for _, c := range u.Conflict {
+		on = append(on, fmt.Sprintf("[target].%s = [source].%s", d.QuoteIdent(c), d.QuoteIdent(c)))
+	}
+	t := bear.NewTemplate(fmt.Sprintf("merge into %s as [target] using (values %s) as [source] (%s) on %s",
+		d.QuoteIdent(u.Table),
+		strings.Join(holders, ", "),
+		strings.Join(columns, ", "),
+		strings.Join(on, " and "),
+	), values...)
+	if len(u.Update) > 0 {
+		pairs := make([]string, 0, len(u.Update))
+		for _, c := range u.Update {
+			pairs = append(pairs, fmt.Sprintf("%s = [source].%s", d.QuoteIdent(c), d.QuoteIdent(c)))
+		}
+		t = t.Appendf(" when matched then update set " + strings.Join(pairs, ", "))
+	}
+	return t.Appendf(fmt.Sprintf(" when not matched then insert (%s) values (%s)%s;",
+		strings.Join(columns, ", "),
+		strings.Join(sources, ", "),
+		u.OutputClause(),
+	))
+}
+
+// Returning renders an output clause of the inserted, or deleted, row values.
+func (d *Dialect) Returning(action bear.Action, columns []string) (string, string) {
+	prefix := "inserted."
+	if action == bear.ActionDelete {
+		prefix = "deleted."
+	}
+	quoted := make([]string, 0, len(columns))
+	for _, c := range columns {
+		quoted = append(quoted, prefix+d.QuoteIdent(c))
+	}
+	return "output " + strings.Join(quoted, ", "), ""
+}
+
+func (d *Dialect) UpdateFrom(m bear.MultiTable) bear.Template {
+	t := bear.NewTemplate("update " + m.Target + " set ").Append(m.Set).Appendf(m.OutputClause() + " from ").Append(m.Table)
+	return m.AppendWhere(t.Append(m.Others.Join(", ", ", ", "")))
+}
+
+func (d *Dialect) DeleteUsing(m bear.MultiTable) bear.Template {
+	t := bear.NewTemplate("delete " + m.Target + m.OutputClause() + " from ").Append(m.Table)
+	return m.AppendWhere(t.Append(m.Others.Join(", ", ", ", "")))
+}
+
+// CreateIndex checks sys.indexes for CheckExists, sql server has no create index if not exists.
+func (d *Dialect) CreateIndex(i bear.Index) string {
+	create := fmt.Sprintf("create index %s on %s(%s);", d.QuoteIdent(i.Name), d.QuoteIdent(i.Table),
+		strings.Join(bear.QuoteIdents(d, i.Columns), ", "))
+	if !i.CheckExists {
+		return create
+	}
+	return fmt.Sprintf("if not exists (select 1 from sys.indexes where name = %s and object_id = object_id(%s)) %s",
+		d.QuoteLiteral(i.Name), d.QuoteLiteral(d.QuoteIdent(i.Table)), create)
+}
@@ -0,0 +1,88 @@
+package mssql_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/medivhyang/bear"
+	"github.com/medivhyang/bear/dialect/mssql"
+)
+
+func assertBuild(t *testing.T, got bear.Template, format string, values ...interface{}) {
+	t.Helper()
+	if got.Format != format {
+		t.Fatalf("format\n got: %s\nwant: %s", got.Format, format)
+	}
+	if !reflect.DeepEqual(got.Values, values) {
+		t.Fatalf("values\n got: %#v\nwant: %#v", got.Values, values)
+	}
+}
+
+func TestWithRecursive(t *testing.T) {
+	tree := bear.NewBuilder().Select("node", "id").Where("id = ?", 1).
+		UnionAll(bear.NewBuilder().Select("node", "node.id").Join("tree", "", "node.parent_id = tree.id"))
+	b := bear.NewBuilder().Dialect(mssql.Name).WithRecursive("tree", []string{"id"}, tree).Select("tree")
+	assertBuild(t, b.Build(),
+		`with [tree]([id]) as (select [id] from [node] where (id = @p1) union all select [node].[id] from [node] inner join [tree] on node.parent_id = tree.id) select * from [tree]`,
+		1)
+}
+
+type user struct {
+	ID   int64 `bear:"pk"`
+	Name string
+}
+
+func TestUpsertConflictDefaultsToKey(t *testing.T) {
+	b := bear.NewBuilder().Dialect(mssql.Name).InsertStruct("user", user{ID: 1, Name: "a"}, false).
+		OnConflictDoUpdate(nil, "name")
+	assertBuild(t, b.Build(),
+		`merge into [user] as [target] using (values (@p1, @p2)) as [source] ([id], [name]) on [target].[id] = [source].[id] when matched then update set [name] = [source].[name] when not matched then insert ([id], [name]) values ([source].[id], [source].[name]);`,
+		int64(1), "a")
+}
+
+func TestUpsertWithoutConflictPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("want panic")
+		}
+	}()
+	bear.NewBuilder().Dialect(mssql.Name).Insert("user", map[string]interface{}{"name": "a"}).OnConflictDoNothing().Build()
+}
+
+func TestMultiTableOutput(t *testing.T) {
+	b := bear.NewBuilder().Dialect(mssql.Name).Delete("post").Using("user", "u").
+		Where("post.user_id = u.id and u.name = ?", "a").Returning("id")
+	assertBuild(t, b.Build(),
+		`delete [post] output deleted.[id] from [post], [user] as [u] where (post.user_id = u.id and u.name = @p1)`,
+		"a")
+}
+
+func TestPaging(t *testing.T) {
+	b := bear.NewBuilder().Dialect(mssql.Name).Select("user", "id").Paging(1, 10)
+	assertBuild(t, b.Build(), `select top (@p1) [id] from [user]`, 10)
+
+	b = bear.NewBuilder().Dialect(mssql.Name).Select("user", "id").Where(bear.Eq("name", "a")).OrderBy("id").Paging(2, 10)
+	assertBuild(t, b.Build(), `select [id] from [user] where ([name] = @p1) order by id offset @p2 rows fetch next @p3 rows only`, "a", 10, 10)
+
+	b = bear.NewBuilder().Dialect(mssql.Name).Select("user", "id").Offset(5)
+	assertBuild(t, b.Build(), `select [id] from [user] order by (select null) offset @p1 rows`, 5)
+
+	b = bear.NewBuilder().Dialect(mssql.Name).Select("user", "id").Union(bear.NewBuilder().Select("admin", "id")).Limit(10)
+	assertBuild(t, b.Build(), `select [id] from [user] union select [id] from [admin] order by 1 offset @p1 rows fetch next @p2 rows only`, 0, 10)
+}
+
+func TestUpsertDoNothing(t *testing.T) {
+	b := bear.NewBuilder().Dialect(mssql.Name).Insert("user", map[string]interface{}{"id": 1, "name": "a"}).
+		OnConflictDoNothing("id").Returning("id")
+	assertBuild(t, b.Build(),
+		`merge into [user] as [target] using (values (@p1, @p2)) as [source] ([id], [name]) on [target].[id] = [source].[id] when not matched then insert ([id], [name]) values ([source].[id], [source].[name]) output inserted.[id];`,
+		1, "a")
+}
+
+func TestUpdateFrom(t *testing.T) {
+	b := bear.NewBuilder().Dialect(mssql.Name).Update("post", map[string]interface{}{"title": "t"}).From("user", "u").
+		Where("post.user_id = u.id").Where(bear.Eq("u.name", "a")).Returning("id")
+	assertBuild(t, b.Build(),
+		`update [post] set [title] = @p1 output inserted.[id] from [post], [user] as [u] where (post.user_id = u.id and [u].[name] = @p2)`,
+		"t", "a")
+}
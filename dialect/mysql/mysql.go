@@ -0,0 +1,161 @@
+package mysql
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/medivhyang/bear"
+)
+
+const Name = "mysql"
+
+func init() {
+	bear.RegisterDialect(Name, &Dialect{})
+}
+
+const DefaultVarcharSize = 255
+
+type Dialect struct {
+	// VarcharSize is the length used for string columns, DefaultVarcharSize if zero.
+	VarcharSize int
+}
+
+func (d *Dialect) MappingType(rt reflect.Type, size int) string {
+	if rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	switch rt.Kind() {
+	case reflect.Bool:
+		return "tinyint(1)"
+	case reflect.Int8:
+		return "tinyint"
+	case reflect.Int16:
+		return "smallint"
+	case reflect.Int32:
+		return "int"
+	case reflect.Int, reflect.Int64:
+		return "bigint"
+	case reflect.Uint8:
+		return "tinyint unsigned"
+	case reflect.Uint16:
+		return "smallint unsigned"
+	case reflect.Uint32:
+		return "int unsigned"
+	case reflect.Uint, reflect.Uint64:
+		return "bigint unsigned"
+	case reflect.Float32:
+		return "float"
+	case reflect.Float64:
+		return "double"
+	case reflect.String:
+		if size <= 0 {
+			size = d.VarcharSize
+		}
+		if size <= 0 {
+			size = DefaultVarcharSize
+		}
+		return fmt.Sprintf("varchar(%d)", size)
+	case reflect.Slice:
+		if rt.Elem().Kind() == reflect.Uint8 {
+			return "longblob"
+		}
+		return ""
+	case reflect.Map:
+		return "json"
+	default:
+		switch rt.String() {
+		case "time.Time":
+			return "datetime(6)"
+		}
+		return ""
+	}
+}
+
+func (d *Dialect) AutoIncrement() string {
+	return "auto_increment"
+}
+
+func (d *Dialect) QuoteIdent(s string) string {
+	return bear.QuoteIdentWith(s, "`", "`")
+}
+
+func (d *Dialect) QuoteLiteral(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+func (d *Dialect) Placeholder(n int) string {
+	return "?"
+}
+
+func (d *Dialect) Paging(t bear.Template, p bear.Page) bear.Template {
+	limit, offset := p.Limit, p.Offset
+	if limit <= 0 {
+		return t.Appendf(" limit 18446744073709551615 offset ?", offset)
+	}
+	if offset <= 0 {
+		return t.Appendf(" limit ?", limit)
+	}
+	return t.Appendf(" limit ? offset ?", limit, offset)
+}
+
+func (d *Dialect) With(recursive bool) string {
+	if recursive {
+		return "with recursive"
+	}
+	return "with"
+}
+
+// Upsert renders on duplicate key update, which applies to any unique key so
+// the conflict columns are not rendered. Skipping is done by a no-op update,
+// or not at all if there is no column to assign.
+func (d *Dialect) Upsert(u bear.Upsert) bear.Template {
+	t := u.Insert(d)
+	update := u.Update
+	if len(update) == 0 {
+		switch {
+		case len(u.Conflict) > 0:
+			update = u.Conflict[:1]
+		case len(u.Columns) > 0:
+			update = u.Columns[:1]
+		default:
+			return t
+		}
+		c := d.QuoteIdent(update[0])
+		return t.Appendf(fmt.Sprintf(" on duplicate key update %s = %s", c, c))
+	}
+	pairs := make([]string, 0, len(update))
+	for _, c := range update {
+		pairs = append(pairs, fmt.Sprintf("%s = values(%s)", d.QuoteIdent(c), d.QuoteIdent(c)))
+	}
+	return t.Appendf(" on duplicate key update " + strings.Join(pairs, ", "))
+}
+
+// Returning is not supported by mysql, inserts fall back to LastInsertId.
+func (d *Dialect) Returning(action bear.Action, columns []string) (string, string) {
+	return "", ""
+}
+
+// UpdateFrom qualifies the set columns with the target, they are ambiguous if
+// another table has a column of the same name.
+func (d *Dialect) UpdateFrom(m bear.MultiTable) bear.Template {
+	pairs := make([]string, 0, len(m.Columns))
+	for _, c := range m.Columns {
+		pairs = append(pairs, fmt.Sprintf("%s.%s = ?", m.Target, d.QuoteIdent(c)))
+	}
+	t := bear.NewTemplate("update ").Append(m.Table).Append(m.Others.Join(", ", ", ", ""))
+	return m.AppendWhere(t.Appendf(" set "+strings.Join(pairs, ", "), m.Set.Values...))
+}
+
+func (d *Dialect) DeleteUsing(m bear.MultiTable) bear.Template {
+	t := bear.NewTemplate("delete " + m.Target + " from ").Append(m.Table)
+	return m.AppendWhere(t.Append(m.Others.Join(", ", ", ", "")))
+}
+
+// CreateIndex ignores CheckExists, mysql has no create index if not exists.
+func (d *Dialect) CreateIndex(i bear.Index) string {
+	return fmt.Sprintf("create index %s on %s(%s);", d.QuoteIdent(i.Name), d.QuoteIdent(i.Table),
+		strings.Join(bear.QuoteIdents(d, i.Columns), ", "))
+}
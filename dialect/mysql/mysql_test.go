@@ -0,0 +1,90 @@
+package mysql_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/medivhyang/bear"
+	"github.com/medivhyang/bear/dialect/mysql"
+)
+
+func assertBuild(t *testing.T, got bear.Template, format string, values ...interface{}) {
+	t.Helper()
+	if got.Format != format {
+		t.Fatalf("format\n got: %s\nwant: %s", got.Format, format)
+	}
+	if (len(got.Values) > 0 || len(values) > 0) && !reflect.DeepEqual(got.Values, values) {
+		t.Fatalf("values\n got: %#v\nwant: %#v", got.Values, values)
+	}
+}
+
+func TestUpsertDoNothing(t *testing.T) {
+	b := bear.NewBuilder().Dialect(mysql.Name).Insert("user", map[string]interface{}{"name": "a"}).OnConflictDoNothing()
+	assertBuild(t, b.Build(), "insert into `user`(`name`) values(?) on duplicate key update `name` = `name`", "a")
+
+	bulk, err := bear.NewBulkInsertBuilder().Dialect(mysql.Name).Table("user").Append([]interface{}{}).OnConflictDoNothing().Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertBuild(t, bulk, "insert into `user`() values()")
+}
+
+func TestUpdateFrom(t *testing.T) {
+	b := bear.NewBuilder().Dialect(mysql.Name).Update("post", map[string]interface{}{"name": "a", "age": 1}).
+		From("user", "u").Where("post.user_id = u.id and u.name = ?", "b")
+	assertBuild(t, b.Build(),
+		"update `post`, `user` as `u` set `post`.`age` = ?, `post`.`name` = ? where (post.user_id = u.id and u.name = ?)",
+		1, "a", "b")
+}
+
+func TestQuoteIdent(t *testing.T) {
+	d := bear.GetDialect(mysql.Name)
+	for s, want := range map[string]string{
+		"user":         "`user`",
+		"db.user.name": "`db`.`user`.`name`",
+		"u.*":          "`u`.*",
+		"we`ird":       "`we``ird`",
+		"`user`":       "`user`",
+		"count(*)":     "count(*)",
+	} {
+		if got := d.QuoteIdent(s); got != want {
+			t.Errorf("QuoteIdent(%q) = %s, want %s", s, got, want)
+		}
+	}
+}
+
+type post struct {
+	ID        int64  `bear:"pk,autoincr"`
+	Title     string `bear:"size=100"`
+	Body      string
+	Score     float64
+	Draft     bool
+	Data      []byte
+	Meta      map[string]interface{}
+	CreatedAt time.Time
+	DeletedAt *time.Time
+}
+
+func TestMappingType(t *testing.T) {
+	b := bear.NewDDLBuilder(mysql.Name).Compact().CreateTableStruct("post", post{}, true)
+	assertBuild(t, b.Build(),
+		"create table if not exists `post` (`id` bigint primary key auto_increment,`title` varchar(100),`body` varchar(255),`score` double,`draft` tinyint(1),`data` longblob,`meta` json,`created_at` datetime(6),`deleted_at` datetime(6));")
+}
+
+func TestPaging(t *testing.T) {
+	b := bear.NewBuilder().Dialect(mysql.Name).Select("user", "id").Where(bear.Eq("name", "a")).OrderBy("id").Paging(2, 10)
+	assertBuild(t, b.Build(), "select `id` from `user` where (`name` = ?) order by id limit ? offset ?", "a", 10, 10)
+}
+
+func TestUpsertDoUpdate(t *testing.T) {
+	b := bear.NewBuilder().Dialect(mysql.Name).Insert("user", map[string]interface{}{"id": 1, "name": "a"}).
+		OnConflictDoUpdate([]string{"id"}, "name")
+	assertBuild(t, b.Build(), "insert into `user`(`id`, `name`) values(?, ?) on duplicate key update `name` = values(`name`)", 1, "a")
+}
+
+func TestDeleteUsing(t *testing.T) {
+	b := bear.NewBuilder().Dialect(mysql.Name).Delete("post").Using("user", "u").
+		Where("post.user_id = u.id").Where(bear.Eq("u.name", "a"))
+	assertBuild(t, b.Build(), "delete `post` from `post`, `user` as `u` where (post.user_id = u.id and `u`.`name` = ?)", "a")
+}
@@ -0,0 +1,139 @@
+package postgres
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	_ "github.com/lib/pq"
+	"github.com/medivhyang/bear"
+)
+
+const Name = "postgres"
+
+func init() {
+	bear.RegisterDialect(Name, &Dialect{})
+}
+
+type Dialect struct{}
+
+func (d *Dialect) MappingType(rt reflect.Type, size int) string {
+	if rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	switch rt.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int8, reflect.Int16, reflect.Uint8:
+		return "smallint"
+	case reflect.Int32, reflect.Uint16:
+		return "integer"
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32:
+		return "bigint"
+	case reflect.Uint64:
+		return "numeric(20)"
+	case reflect.Float32:
+		return "real"
+	case reflect.Float64:
+		return "double precision"
+	case reflect.String:
+		if size > 0 {
+			return "varchar(" + strconv.Itoa(size) + ")"
+		}
+		return "text"
+	case reflect.Slice:
+		if rt.Elem().Kind() == reflect.Uint8 {
+			return "bytea"
+		}
+		return ""
+	case reflect.Map:
+		return "jsonb"
+	default:
+		switch rt.String() {
+		case "time.Time":
+			return "timestamptz"
+		}
+		return ""
+	}
+}
+
+func (d *Dialect) AutoIncrement() string {
+	return "generated by default as identity"
+}
+
+func (d *Dialect) QuoteIdent(s string) string {
+	return bear.QuoteIdentWith(s, `"`, `"`)
+}
+
+func (d *Dialect) QuoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+func (d *Dialect) Placeholder(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+func (d *Dialect) Paging(t bear.Template, p bear.Page) bear.Template {
+	if p.Limit > 0 {
+		t = t.Appendf(" limit ?", p.Limit)
+	}
+	if p.Offset > 0 {
+		t = t.Appendf(" offset ?", p.Offset)
+	}
+	return t
+}
+
+func (d *Dialect) With(recursive bool) string {
+	if recursive {
+		return "with recursive"
+	}
+	return "with"
+}
+
+func (d *Dialect) Upsert(u bear.Upsert) bear.Template {
+	t := u.Insert(d)
+	conflict := make([]string, 0, len(u.Conflict))
+	for _, c := range u.Conflict {
+		conflict = append(conflict, d.QuoteIdent(c))
+	}
+	t = t.Appendf(" on conflict")
+	if len(conflict) > 0 {
+		t = t.Appendf(" (" + strings.Join(conflict, ", ") + ")")
+	}
+	if len(u.Update) == 0 {
+		return t.Appendf(" do nothing")
+	}
+	pairs := make([]string, 0, len(u.Update))
+	for _, c := range u.Update {
+		pairs = append(pairs, fmt.Sprintf("%s = excluded.%s", d.QuoteIdent(c), d.QuoteIdent(c)))
+	}
+	return t.Appendf(" do update set " + strings.Join(pairs, ", "))
+}
+
+func (d *Dialect) Returning(action bear.Action, columns []string) (string, string) {
+	quoted := make([]string, 0, len(columns))
+	for _, c := range columns {
+		quoted = append(quoted, d.QuoteIdent(c))
+	}
+	return "", "returning " + strings.Join(quoted, ", ")
+}
+
+func (d *Dialect) UpdateFrom(m bear.MultiTable) bear.Template {
+	t := bear.NewTemplate("update ").Append(m.Table).Appendf(" set ").Append(m.Set)
+	return m.AppendWhere(t.Append(m.Others.Join(", ", " from ", "")))
+}
+
+func (d *Dialect) DeleteUsing(m bear.MultiTable) bear.Template {
+	t := bear.NewTemplate("delete from ").Append(m.Table)
+	return m.AppendWhere(t.Append(m.Others.Join(", ", " using ", "")))
+}
+
+func (d *Dialect) CreateIndex(i bear.Index) string {
+	create := "create index"
+	if i.CheckExists {
+		create += " if not exists"
+	}
+	return fmt.Sprintf("%s %s on %s(%s);", create, d.QuoteIdent(i.Name), d.QuoteIdent(i.Table),
+		strings.Join(bear.QuoteIdents(d, i.Columns), ", "))
+}
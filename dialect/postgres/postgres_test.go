@@ -0,0 +1,64 @@
+package postgres_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/medivhyang/bear"
+	"github.com/medivhyang/bear/dialect/postgres"
+)
+
+func assertBuild(t *testing.T, got bear.Template, format string, values ...interface{}) {
+	t.Helper()
+	if got.Format != format {
+		t.Fatalf("format\n got: %s\nwant: %s", got.Format, format)
+	}
+	if !reflect.DeepEqual(got.Values, values) {
+		t.Fatalf("values\n got: %#v\nwant: %#v", got.Values, values)
+	}
+}
+
+func TestArrayPlaceholders(t *testing.T) {
+	sub := bear.NewBuilder().Select("tag", "post_id").Where("name = any(array[?, ?])", "a", "b")
+	b := bear.NewBuilder().Dialect(postgres.Name).Select("post").
+		Where("id = any(array[?, ?]) and x = ?", 1, 2, 3).
+		WhereIn("id", sub)
+	assertBuild(t, b.Build(),
+		`select * from "post" where (id = any(array[$1, $2]) and x = $3 and "id" in (select "post_id" from "tag" where (name = any(array[$4, $5]))))`,
+		1, 2, 3, "a", "b")
+}
+
+func TestPlaceholderNumbering(t *testing.T) {
+	adult := bear.NewBuilder().Select("user", "id").Where(bear.Gt("age", 18))
+	tagged := bear.NewBuilder().Select("tag", "post_id").Where(bear.Eq("name", "go"))
+	pinned := bear.NewBuilder().Select("post", "id").Where(bear.Eq("pinned", true))
+	b := bear.NewBuilder().Dialect(postgres.Name).With("adult", adult).Select("post", "id").
+		Where(bear.Eq("state", 1)).WhereIn("id", tagged).Where("user_id in (select id from adult)").
+		Union(pinned).Paging(1, 5)
+	assertBuild(t, b.Build(),
+		`with "adult" as (select "id" from "user" where ("age" > $1)) select "id" from "post" where ("state" = $2 and "id" in (select "post_id" from "tag" where ("name" = $3)) and user_id in (select id from adult)) union select "id" from "post" where ("pinned" = $4) limit $5`,
+		18, 1, "go", true, 5)
+}
+
+func TestUpsert(t *testing.T) {
+	columns := map[string]interface{}{"id": 1, "name": "a"}
+	b := bear.NewBuilder().Dialect(postgres.Name).Insert("user", columns).OnConflictDoUpdate([]string{"id"}, "name")
+	assertBuild(t, b.Build(),
+		`insert into "user"("id", "name") values($1, $2) on conflict ("id") do update set "name" = excluded."name"`,
+		1, "a")
+	b = bear.NewBuilder().Dialect(postgres.Name).Insert("user", columns).OnConflictDoNothing("id")
+	assertBuild(t, b.Build(), `insert into "user"("id", "name") values($1, $2) on conflict ("id") do nothing`, 1, "a")
+}
+
+func TestMultiTable(t *testing.T) {
+	b := bear.NewBuilder().Dialect(postgres.Name).Update("post", map[string]interface{}{"title": "t"}).From("user", "u").
+		Where("post.user_id = u.id").Where(bear.Eq("u.name", "a"))
+	assertBuild(t, b.Build(),
+		`update "post" set "title" = $1 from "user" as "u" where (post.user_id = u.id and "u"."name" = $2)`,
+		"t", "a")
+	b = bear.NewBuilder().Dialect(postgres.Name).Delete("post").Using("user", "u").
+		Where("post.user_id = u.id").Where(bear.Eq("u.name", "a")).Returning("id")
+	assertBuild(t, b.Build(),
+		`delete from "post" using "user" as "u" where (post.user_id = u.id and "u"."name" = $1) returning "id"`,
+		"a")
+}
@@ -5,6 +5,7 @@ import (
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/medivhyang/bear"
 	"reflect"
+	"strings"
 )
 
 const Name = "sqlite3"
@@ -15,7 +16,7 @@ func init() {
 
 type Dialect struct{}
 
-func (d *Dialect) MappingType(rt reflect.Type) string {
+func (d *Dialect) MappingType(rt reflect.Type, size int) string {
 	if rt.Kind() == reflect.Ptr {
 		rt = rt.Elem()
 	}
@@ -37,6 +38,83 @@ func (d *Dialect) MappingType(rt reflect.Type) string {
 	}
 }
 
-func (d *Dialect) Quote(s string) string {
-	return fmt.Sprintf("'%s'", s)
+func (d *Dialect) AutoIncrement() string {
+	return "autoincrement"
+}
+
+func (d *Dialect) QuoteIdent(s string) string {
+	return bear.QuoteIdentWith(s, `"`, `"`)
+}
+
+func (d *Dialect) QuoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+func (d *Dialect) Placeholder(n int) string {
+	return "?"
+}
+
+func (d *Dialect) Paging(t bear.Template, p bear.Page) bear.Template {
+	limit, offset := p.Limit, p.Offset
+	if limit <= 0 {
+		return t.Appendf(" limit -1 offset ?", offset)
+	}
+	if offset <= 0 {
+		return t.Appendf(" limit ?", limit)
+	}
+	return t.Appendf(" limit ? offset ?", limit, offset)
+}
+
+func (d *Dialect) With(recursive bool) string {
+	if recursive {
+		return "with recursive"
+	}
+	return "with"
+}
+
+func (d *Dialect) Upsert(u bear.Upsert) bear.Template {
+	t := u.Insert(d)
+	conflict := make([]string, 0, len(u.Conflict))
+	for _, c := range u.Conflict {
+		conflict = append(conflict, d.QuoteIdent(c))
+	}
+	t = t.Appendf(" on conflict")
+	if len(conflict) > 0 {
+		t = t.Appendf(" (" + strings.Join(conflict, ", ") + ")")
+	}
+	if len(u.Update) == 0 {
+		return t.Appendf(" do nothing")
+	}
+	pairs := make([]string, 0, len(u.Update))
+	for _, c := range u.Update {
+		pairs = append(pairs, fmt.Sprintf("%s = excluded.%s", d.QuoteIdent(c), d.QuoteIdent(c)))
+	}
+	return t.Appendf(" do update set " + strings.Join(pairs, ", "))
+}
+
+// Returning is not supported by the bundled sqlite version, inserts fall back to LastInsertId.
+func (d *Dialect) Returning(action bear.Action, columns []string) (string, string) {
+	return "", ""
+}
+
+// UpdateFrom renders an exists subquery over the other tables, the bundled
+// sqlite version has no update from.
+func (d *Dialect) UpdateFrom(m bear.MultiTable) bear.Template {
+	t := bear.NewTemplate("update ").Append(m.Table).Appendf(" set ").Append(m.Set)
+	return t.Append(m.AppendWhere(m.Others.Join(", ", " where exists (select 1 from ", "")).Appendf(")"))
+}
+
+// DeleteUsing renders an exists subquery over the other tables, sqlite has no delete using.
+func (d *Dialect) DeleteUsing(m bear.MultiTable) bear.Template {
+	t := bear.NewTemplate("delete from ").Append(m.Table)
+	return t.Append(m.AppendWhere(m.Others.Join(", ", " where exists (select 1 from ", "")).Appendf(")"))
+}
+
+func (d *Dialect) CreateIndex(i bear.Index) string {
+	create := "create index"
+	if i.CheckExists {
+		create += " if not exists"
+	}
+	return fmt.Sprintf("%s %s on %s(%s);", create, d.QuoteIdent(i.Name), d.QuoteIdent(i.Table),
+		strings.Join(bear.QuoteIdents(d, i.Columns), ", "))
 }
@@ -0,0 +1,42 @@
+package sqlite3_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/medivhyang/bear"
+	"github.com/medivhyang/bear/dialect/sqlite3"
+)
+
+func assertBuild(t *testing.T, got bear.Template, format string, values ...interface{}) {
+	t.Helper()
+	if got.Format != format {
+		t.Fatalf("format\n got: %s\nwant: %s", got.Format, format)
+	}
+	if !reflect.DeepEqual(got.Values, values) {
+		t.Fatalf("values\n got: %#v\nwant: %#v", got.Values, values)
+	}
+}
+
+func TestUpsert(t *testing.T) {
+	columns := map[string]interface{}{"id": 1, "name": "a"}
+	b := bear.NewBuilder().Dialect(sqlite3.Name).Insert("user", columns).OnConflictDoUpdate([]string{"id"}, "name")
+	assertBuild(t, b.Build(),
+		`insert into "user"("id", "name") values(?, ?) on conflict ("id") do update set "name" = excluded."name"`,
+		1, "a")
+	b = bear.NewBuilder().Dialect(sqlite3.Name).Insert("user", columns).OnConflictDoNothing("id")
+	assertBuild(t, b.Build(), `insert into "user"("id", "name") values(?, ?) on conflict ("id") do nothing`, 1, "a")
+}
+
+func TestMultiTable(t *testing.T) {
+	b := bear.NewBuilder().Dialect(sqlite3.Name).Update("post", map[string]interface{}{"title": "t"}).From("user", "u").
+		Where("post.user_id = u.id").Where(bear.Eq("u.name", "a"))
+	assertBuild(t, b.Build(),
+		`update "post" set "title" = ? where exists (select 1 from "user" as "u" where (post.user_id = u.id and "u"."name" = ?))`,
+		"t", "a")
+	b = bear.NewBuilder().Dialect(sqlite3.Name).Delete("post").Using("user", "u").
+		Where("post.user_id = u.id").Where(bear.Eq("u.name", "a"))
+	assertBuild(t, b.Build(),
+		`delete from "post" where exists (select 1 from "user" as "u" where (post.user_id = u.id and "u"."name" = ?))`,
+		"a")
+}
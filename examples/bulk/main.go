@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"github.com/medivhyang/bear"
+	_ "github.com/medivhyang/bear/dialect/sqlite3"
 )
 
 func main() {
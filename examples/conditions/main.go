@@ -15,5 +15,5 @@ func main() {
 		"human": true,
 	})
 	cc = cc.AppendIn("likes", "cat", "dog", "tiger")
-	fmt.Println(cc.JoinAnd())
+	fmt.Println(cc.JoinAnd().Expand(bear.GetDefaultDialect()))
 }
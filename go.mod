@@ -1,8 +1,16 @@
 module github.com/medivhyang/bear
 
-go 1.14
+go 1.18
 
 require (
+	github.com/denisenkom/go-mssqldb v0.9.0
+	github.com/go-sql-driver/mysql v1.5.0
+	github.com/lib/pq v1.8.0
 	github.com/mattn/go-sqlite3 v1.14.0
 	github.com/medivhyang/duck v0.0.10
 )
+
+require (
+	github.com/golang-sql/civil v0.0.0-20190719163853-cb61b32ac6fe // indirect
+	golang.org/x/crypto v0.0.0-20190325154230-a5d413f7728c // indirect
+)
@@ -0,0 +1,33 @@
+package bear
+
+import "github.com/medivhyang/duck/naming"
+
+// Naming maps a struct field name to a column name, it is used by every struct
+// aware API for fields without a name= tag. Columns are cached per struct type
+// for SnakeNaming, CamelNaming and ExactNaming only.
+type Naming func(field string) string
+
+var (
+	SnakeNaming Naming = naming.ToSnake
+	CamelNaming Naming = naming.ToCamel
+	ExactNaming Naming = func(field string) string { return field }
+)
+
+var defaultNaming = SnakeNaming
+
+func SetDefaultNaming(n Naming) {
+	if n != nil {
+		defaultNaming = n
+	}
+}
+
+func GetDefaultNaming() Naming {
+	return defaultNaming
+}
+
+func (n Naming) orDefault() Naming {
+	if n == nil {
+		return defaultNaming
+	}
+	return n
+}
@@ -0,0 +1,111 @@
+package bear
+
+import (
+	"context"
+	"database/sql"
+)
+
+// QueryOne returns the first row of the query as a T, a struct, a
+// map[string]interface{} or a scalar, and sql.ErrNoRows if there is none.
+func QueryOne[T any](ctx context.Context, db DB, b *Builder) (T, error) {
+	b = b.returningKey()
+	var item T
+	if b.queryReturnsLastInsertID() {
+		err := b.queryLastInsertID(ctx, db, &item)
+		return item, err
+	}
+	c, err := db.Iterate(ctx, b.Build())
+	if err != nil {
+		return item, err
+	}
+	defer c.Close()
+	if !c.Next() {
+		if err := c.Err(); err != nil {
+			return item, err
+		}
+		return item, sql.ErrNoRows
+	}
+	if err := c.Scan(&item); err != nil {
+		return item, err
+	}
+	return item, c.Close()
+}
+
+// QueryAll returns every row of the query as a T, see QueryOne.
+func QueryAll[T any](ctx context.Context, db DB, b *Builder) ([]T, error) {
+	b = b.returningKey()
+	var items []T
+	if b.queryReturnsLastInsertID() {
+		item, err := QueryOne[T](ctx, db, b)
+		if err != nil {
+			return nil, err
+		}
+		return append(items, item), nil
+	}
+	err := Each(ctx, db, b.Build(), func(item T, abort func()) error {
+		items = append(items, item)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// QueryScalar returns the first column of the first row of the query,
+// and sql.ErrNoRows if there is none.
+func QueryScalar[T any](ctx context.Context, db DB, b *Builder) (T, error) {
+	b = b.returningKey()
+	var value T
+	if b.queryReturnsLastInsertID() {
+		err := b.queryLastInsertID(ctx, db, &value)
+		return value, err
+	}
+	c, err := db.Iterate(ctx, b.Build())
+	if err != nil {
+		return value, err
+	}
+	defer c.Close()
+	if !c.Next() {
+		if err := c.Err(); err != nil {
+			return value, err
+		}
+		return value, sql.ErrNoRows
+	}
+	columns, err := c.rows.Raw.Columns()
+	if err != nil {
+		return value, err
+	}
+	targets := make([]interface{}, len(columns))
+	targets[0] = &value
+	for i := 1; i < len(targets); i++ {
+		targets[i] = new(interface{})
+	}
+	if err := c.rows.Raw.Scan(targets...); err != nil {
+		return value, err
+	}
+	return value, c.Close()
+}
+
+// QueryMap returns the rows of a query of two columns as a map from the first
+// column to the second, later rows overriding earlier ones with the same key.
+func QueryMap[K comparable, V any](ctx context.Context, db DB, b *Builder) (map[K]V, error) {
+	c, err := db.Iterate(ctx, b.Build())
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+	m := map[K]V{}
+	for c.Next() {
+		var k K
+		var v V
+		if err := c.rows.Raw.Scan(&k, &v); err != nil {
+			return nil, err
+		}
+		m[k] = v
+	}
+	if err := c.Err(); err != nil {
+		return nil, err
+	}
+	return m, c.Close()
+}
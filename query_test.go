@@ -0,0 +1,117 @@
+package bear_test
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"github.com/medivhyang/bear"
+	_ "github.com/medivhyang/bear/dialect/sqlite3"
+)
+
+type queryUser struct {
+	ID   int64 `bear:"pk,autoincr"`
+	Name string
+	Age  int
+}
+
+func openQueryDB(t *testing.T) bear.DB {
+	t.Helper()
+	raw, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw.SetMaxOpenConns(1)
+	t.Cleanup(func() { raw.Close() })
+	if _, err := raw.Exec("create table user (id integer primary key autoincrement, name text, age integer)"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := raw.Exec("insert into user (name, age) values ('a', 1), ('b', 2), ('c', 2)"); err != nil {
+		t.Fatal(err)
+	}
+	return bear.NewDB(raw)
+}
+
+func TestQueryOne(t *testing.T) {
+	db, ctx := openQueryDB(t), context.Background()
+	u, err := bear.QueryOne[queryUser](ctx, db, bear.NewBuilder().Select("user").Where(bear.Eq("name", "b")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u != (queryUser{ID: 2, Name: "b", Age: 2}) {
+		t.Fatalf("got %+v", u)
+	}
+	p, err := bear.QueryOne[*queryUser](ctx, db, bear.NewBuilder().Select("user").OrderBy("id desc"))
+	if err != nil || p == nil || p.ID != 3 {
+		t.Fatalf("pointer: got %+v, %v", p, err)
+	}
+	m, err := bear.QueryOne[map[string]interface{}](ctx, db, bear.NewBuilder().Select("user", "name").Where(bear.Eq("id", 1)))
+	if err != nil || !reflect.DeepEqual(m, map[string]interface{}{"name": "a"}) {
+		t.Fatalf("map: got %v, %v", m, err)
+	}
+	if _, err := bear.QueryOne[queryUser](ctx, db, bear.NewBuilder().Select("user").Where(bear.Eq("id", 0))); err != sql.ErrNoRows {
+		t.Fatalf("no rows: got %v", err)
+	}
+}
+
+func TestQueryOneInsertStruct(t *testing.T) {
+	db, ctx := openQueryDB(t), context.Background()
+	u, err := bear.QueryOne[queryUser](ctx, db, bear.NewBuilder().InsertStruct("user", queryUser{Name: "d", Age: 4}, false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.ID != 4 {
+		t.Fatalf("got %+v, want the last insert id", u)
+	}
+}
+
+func TestQueryAll(t *testing.T) {
+	db, ctx := openQueryDB(t), context.Background()
+	users, err := bear.QueryAll[queryUser](ctx, db, bear.NewBuilder().Select("user").Where(bear.Eq("age", 2)).OrderBy("id"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []queryUser{{ID: 2, Name: "b", Age: 2}, {ID: 3, Name: "c", Age: 2}}
+	if !reflect.DeepEqual(users, want) {
+		t.Fatalf("got %+v", users)
+	}
+	users, err = bear.QueryAll[queryUser](ctx, db, bear.NewBuilder().Select("user").Where(bear.Eq("age", 9)))
+	if err != nil || len(users) != 0 {
+		t.Fatalf("no rows: got %+v, %v", users, err)
+	}
+}
+
+func TestQueryScalar(t *testing.T) {
+	db, ctx := openQueryDB(t), context.Background()
+	n, err := bear.QueryScalar[int](ctx, db, bear.NewBuilder().Select("user", "count(*)"))
+	if err != nil || n != 3 {
+		t.Fatalf("got %d, %v", n, err)
+	}
+	name, err := bear.QueryScalar[string](ctx, db, bear.NewBuilder().Select("user", "name", "age").Where(bear.Eq("id", 3)))
+	if err != nil || name != "c" {
+		t.Fatalf("first column: got %q, %v", name, err)
+	}
+	if _, err := bear.QueryScalar[string](ctx, db, bear.NewBuilder().Select("user", "name").Where(bear.Eq("id", 0))); err != sql.ErrNoRows {
+		t.Fatalf("no rows: got %v", err)
+	}
+	id, err := bear.QueryScalar[int64](ctx, db, bear.NewBuilder().InsertStruct("user", queryUser{Name: "d"}, false))
+	if err != nil || id != 4 {
+		t.Fatalf("insert: got %d, %v", id, err)
+	}
+}
+
+func TestQueryMap(t *testing.T) {
+	db, ctx := openQueryDB(t), context.Background()
+	m, err := bear.QueryMap[string, int](ctx, db, bear.NewBuilder().Select("user", "name", "age").OrderBy("id"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(m, map[string]int{"a": 1, "b": 2, "c": 2}) {
+		t.Fatalf("got %v", m)
+	}
+	byAge, err := bear.QueryMap[int, string](ctx, db, bear.NewBuilder().Select("user", "age", "name").OrderBy("id"))
+	if err != nil || !reflect.DeepEqual(byAge, map[int]string{1: "a", 2: "c"}) {
+		t.Fatalf("later rows override: got %v, %v", byAge, err)
+	}
+}
@@ -3,20 +3,38 @@ package bear
 import (
 	"database/sql"
 	"reflect"
+	"strings"
 
-	"github.com/medivhyang/duck/naming"
 	"github.com/medivhyang/duck/reflectutil"
 )
 
 type Rows struct {
-	Raw *sql.Rows
+	Raw    *sql.Rows
+	naming Naming
+	strict bool
 }
 
 func NewRows(rows *sql.Rows) *Rows {
 	return &Rows{Raw: rows}
 }
 
+// Naming sets the column naming used to bind structs.
+func (r *Rows) Naming(n Naming) *Rows {
+	r.naming = n
+	return r
+}
+
+// Strict makes struct binding fail on columns without field and on fields
+// without column, except pointers and fields of pointers to struct.
+func (r *Rows) Strict(strict bool) *Rows {
+	r.strict = strict
+	return r
+}
+
+// Scan calls callback for every row until it returns an error or calls abort,
+// and closes the rows.
 func (r *Rows) Scan(callback func(scan func(...interface{}) error, abort func()) error) error {
+	defer r.Raw.Close()
 	if callback == nil {
 		return nil
 	}
@@ -30,9 +48,14 @@ func (r *Rows) Scan(callback func(scan func(...interface{}) error, abort func())
 			break
 		}
 	}
+	if err := r.Raw.Err(); err != nil {
+		return err
+	}
 	return r.Raw.Close()
 }
 
+// Bind scans the rows into i, which is a pointer to a map[string]interface{},
+// a struct, a scalar, or a slice of them or of struct pointers.
 func (r *Rows) Bind(i interface{}) error {
 	rv := reflect.ValueOf(i)
 	if rv.Kind() != reflect.Ptr {
@@ -41,48 +64,40 @@ func (r *Rows) Bind(i interface{}) error {
 	rv2 := reflectutil.DeepUnrefAndNewValue(rv)
 	switch rv2.Interface().(type) {
 	case map[string]interface{}:
-		if !rv.CanSet() {
-			panic("bear: query: can not set value")
-		}
 		m, err := r.Map()
 		if err != nil {
 			return err
 		}
-		rv.Set(reflect.ValueOf(m))
+		rv2.Set(reflect.ValueOf(m))
+	case []map[string]interface{}:
+		ss, err := r.MapSlice()
+		if err != nil {
+			return err
+		}
+		rv2.Set(reflect.ValueOf(ss))
+	case []byte:
+		return r.Scalar(rv2.Addr().Interface())
 	default:
-		switch rv2.Kind() {
-		case reflect.Struct:
-			if err := r.Struct(rv2); err != nil {
-				return err
-			}
-		case reflect.Slice:
-			switch rv2.Type().Elem().Kind() {
-			case reflect.Map:
-				ss, err := r.MapSlice()
-				if err != nil {
-					return err
-				}
-				rv2.Set(reflect.ValueOf(ss))
-			case reflect.Struct:
-				if err := r.StructSlice(rv2); err != nil {
-					return err
-				}
-			default:
-				if err := r.ScalarSlice(rv2); err != nil {
-					return err
-				}
-			}
+		switch {
+		case isNestedStruct(rv2.Type()):
+			return r.Struct(rv2.Addr().Interface())
+		case rv2.Kind() == reflect.Slice && isNestedStruct(rv2.Type().Elem()):
+			return r.StructSlice(rv2.Addr().Interface())
+		case rv2.Kind() == reflect.Slice:
+			return r.ScalarSlice(rv2.Addr().Interface())
 		default:
-			if err := r.Scalar(rv2); err != nil {
-				return err
-			}
+			return r.Scalar(rv2.Addr().Interface())
 		}
 	}
 	return nil
 }
 
 func (r *Rows) Scalar(value interface{}) error {
+	defer r.Raw.Close()
 	if !r.Raw.Next() {
+		if err := r.Raw.Err(); err != nil {
+			return err
+		}
 		return sql.ErrNoRows
 	}
 	if err := r.Raw.Scan(value); err != nil {
@@ -92,6 +107,7 @@ func (r *Rows) Scalar(value interface{}) error {
 }
 
 func (r *Rows) ScalarSlice(slice interface{}) error {
+	defer r.Raw.Close()
 	reflectValue := reflect.ValueOf(slice)
 	if reflectValue.Kind() != reflect.Ptr {
 		return newError("scan rows to values", "require pointer type")
@@ -110,11 +126,15 @@ func (r *Rows) ScalarSlice(slice interface{}) error {
 		}
 		reflectValue.Set(reflect.Append(reflectValue, item.Elem()))
 	}
+	if err := r.Raw.Err(); err != nil {
+		return err
+	}
 
 	return r.Raw.Close()
 }
 
 func (r *Rows) Map() (map[string]interface{}, error) {
+	defer r.Raw.Close()
 	columns, err := r.Raw.Columns()
 	if err != nil {
 		return nil, err
@@ -127,6 +147,9 @@ func (r *Rows) Map() (map[string]interface{}, error) {
 	}
 
 	if !r.Raw.Next() {
+		if err := r.Raw.Err(); err != nil {
+			return nil, err
+		}
 		return nil, sql.ErrNoRows
 	}
 	if err := r.Raw.Scan(values...); err != nil {
@@ -145,6 +168,7 @@ func (r *Rows) Map() (map[string]interface{}, error) {
 }
 
 func (r *Rows) MapSlice() ([]map[string]interface{}, error) {
+	defer r.Raw.Close()
 	columns, err := r.Raw.Columns()
 	if err != nil {
 		return nil, err
@@ -167,6 +191,9 @@ func (r *Rows) MapSlice() ([]map[string]interface{}, error) {
 		}
 		items = append(items, item)
 	}
+	if err := r.Raw.Err(); err != nil {
+		return nil, err
+	}
 
 	if err := r.Raw.Close(); err != nil {
 		return nil, err
@@ -176,93 +203,155 @@ func (r *Rows) MapSlice() ([]map[string]interface{}, error) {
 }
 
 func (r *Rows) Struct(i interface{}) error {
+	defer r.Raw.Close()
 	rv := reflect.ValueOf(i)
 	if rv.Kind() != reflect.Ptr {
 		return newError("rows to struct", "require pointer type")
 	}
+	rv = reflectutil.DeepUnrefAndNewValue(rv)
+	if rv.Kind() != reflect.Struct {
+		return newError("rows to struct", "require struct type")
+	}
 	cc, err := r.Raw.Columns()
 	if err != nil {
 		return err
 	}
-	vv := make([]interface{}, 0, len(cc))
-	fieldMap := r.getFieldMap(i)
-	for _, c := range cc {
-		if f, ok := fieldMap[c]; ok {
-			vv = append(vv, f)
-		} else {
-			var tmp interface{}
-			vv = append(vv, &tmp)
-		}
+	ff, err := r.columnFields(cc, rv.Type())
+	if err != nil {
+		return err
 	}
 	if !r.Raw.Next() {
+		if err := r.Raw.Err(); err != nil {
+			return err
+		}
 		return sql.ErrNoRows
 	}
-	if err := r.Raw.Scan(vv...); err != nil {
+	if err := r.scanStruct(rv, ff); err != nil {
 		return err
 	}
-	if err := r.Raw.Close(); err != nil {
-		return err
-	}
-	return nil
+	return r.Raw.Close()
 }
 
+// StructSlice appends every row to i, a pointer to a []T or []*T of structs.
 func (r *Rows) StructSlice(i interface{}) error {
+	defer r.Raw.Close()
 	rv := reflect.ValueOf(i)
 	if rv.Kind() != reflect.Ptr {
 		return newError("rows to struct slice", "require pointer type")
 	}
-	rv2 := reflectutil.DeepUnrefAndNewValue(rv)
-	if rv2.Kind() != reflect.Slice {
+	rv = reflectutil.DeepUnrefAndNewValue(rv)
+	if rv.Kind() != reflect.Slice {
 		return newError("rows to struct slice", "require slice type")
 	}
+	elemType, structType := rv.Type().Elem(), rv.Type().Elem()
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return newError("rows to struct slice", "require struct slice type")
+	}
 	cc, err := r.Raw.Columns()
 	if err != nil {
 		return err
 	}
-	vv := make([]interface{}, 0, len(cc))
+	ff, err := r.columnFields(cc, structType)
+	if err != nil {
+		return err
+	}
 	for r.Raw.Next() {
-		item := reflect.New(rv2.Type().Elem())
-		fieldMap := r.getFieldMap(item)
-		for _, c := range cc {
-			if v, ok := fieldMap[c]; ok {
-				vv = append(vv, v)
-			} else {
-				var temp interface{}
-				vv = append(vv, &temp)
-			}
-		}
-		if err := r.Raw.Scan(vv...); err != nil {
+		item := reflect.New(structType)
+		if err := r.scanStruct(item.Elem(), ff); err != nil {
 			return err
 		}
-		rv.Set(reflect.Append(rv, item))
+		if elemType.Kind() == reflect.Ptr {
+			rv.Set(reflect.Append(rv, item))
+		} else {
+			rv.Set(reflect.Append(rv, item.Elem()))
+		}
 	}
-	if err := r.Raw.Close(); err != nil {
+	if err := r.Raw.Err(); err != nil {
 		return err
 	}
-	return nil
+	return r.Raw.Close()
 }
 
-func (*Rows) getFieldMap(i interface{}) map[string]interface{} {
-	fieldMap := reflectutil.ParseStructToMap(i)
-	copyFieldMap := make(map[string]interface{}, len(fieldMap))
-	for k, v := range fieldMap {
-		copyFieldMap[k] = v
-	}
-	fieldTagMap := reflectutil.ParseStructChildTags(i, TagKey, TagItemSep, TagKVSep, TagChildKeyName)
-	for name, value := range copyFieldMap {
-		tags := fieldTagMap[name]
-		if tags != nil {
-			s := tags[TagChildKeyName]
-			if s != "" {
-				fieldMap[s] = value
-				delete(fieldMap, name)
-				continue
-			}
+// columnFields returns the field bound to every column of the struct type rt,
+// nil for columns without field. It is computed once per query.
+func (r *Rows) columnFields(columns []string, rt reflect.Type) ([]*field, error) {
+	cs := structColumns(rt, r.naming)
+	ff := make([]*field, len(columns))
+	for i, c := range columns {
+		if j, ok := cs.byColumn[c]; ok {
+			ff[i] = &cs.fields[j]
 		}
-		if naming.ToSnake(name) != name {
-			fieldMap[naming.ToSnake(name)] = value
-			delete(fieldMap, name)
+	}
+	if r.strict {
+		if err := checkColumnFields(columns, ff, cs); err != nil {
+			return nil, err
+		}
+	}
+	return ff, nil
+}
+
+func checkColumnFields(columns []string, ff []*field, cs *columnSet) error {
+	var unmapped, unfilled []string
+	filled := make(map[string]bool, len(ff))
+	for i, f := range ff {
+		if f == nil {
+			unmapped = append(unmapped, columns[i])
+			continue
+		}
+		filled[columns[i]] = true
+	}
+	for i, f := range cs.fields {
+		c := cs.columns[i]
+		if filled[c] || f.optional() || f.Type.Kind() == reflect.Ptr {
+			continue
 		}
+		unfilled = append(unfilled, f.Name+"("+c+")")
+	}
+	if len(unmapped) == 0 && len(unfilled) == 0 {
+		return nil
+	}
+	return newError("rows", "strict binding: unmapped columns [%s], unfilled fields [%s]",
+		strings.Join(unmapped, ", "), strings.Join(unfilled, ", "))
+}
+
+// scanStruct scans the current row into the struct value rv. Fields behind a
+// nil pointer to struct are scanned aside and the pointer is only allocated
+// when one of them is not null.
+func (r *Rows) scanStruct(rv reflect.Value, ff []*field) error {
+	targets := make([]interface{}, len(ff))
+	for i, f := range ff {
+		switch {
+		case f == nil:
+			targets[i] = new(interface{})
+		case f.optional():
+			targets[i] = reflect.New(reflect.PtrTo(f.Type)).Interface()
+		default:
+			targets[i] = rv.FieldByIndex(f.Index).Addr().Interface()
+		}
+	}
+	if err := r.Raw.Scan(targets...); err != nil {
+		return err
+	}
+	for i, f := range ff {
+		if f == nil || !f.optional() {
+			continue
+		}
+		if v := reflect.ValueOf(targets[i]).Elem(); !v.IsNil() {
+			fieldByIndexAlloc(rv, f.Index).Set(v.Elem())
+		}
+	}
+	return nil
+}
+
+func getFieldMap(i interface{}, n Naming) map[string]interface{} {
+	rv := reflectutil.DeepUnrefValue(reflect.ValueOf(i))
+	cs := structColumns(rv.Type(), n)
+	fieldMap := make(map[string]interface{}, len(cs.fields))
+	for i, f := range cs.fields {
+		fieldMap[cs.columns[i]] = fieldByIndexAlloc(rv, f.Index).Addr().Interface()
 	}
 	return fieldMap
 }
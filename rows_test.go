@@ -0,0 +1,507 @@
+package bear
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+type rowsUser struct {
+	ID        int64
+	UserName  string
+	Email     string
+	Age       int
+	CreatedBy string
+}
+
+func openRowsDB(tb testing.TB, n int) *sql.DB {
+	tb.Helper()
+	raw, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	raw.SetMaxOpenConns(1)
+	tb.Cleanup(func() { raw.Close() })
+	if _, err := raw.Exec("create table user (id integer, user_name text, email text, age integer, created_by text)"); err != nil {
+		tb.Fatal(err)
+	}
+	for i := 1; i <= n; i++ {
+		if _, err := raw.Exec("insert into user values (?, ?, ?, ?, ?)", i, "u", "e", i, "c"); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	return raw
+}
+
+func queryRows(tb testing.TB, raw *sql.DB, query string, args ...interface{}) *Rows {
+	tb.Helper()
+	rows, err := raw.Query(query, args...)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return NewRows(rows)
+}
+
+// structSlicePerRow binds rows the way StructSlice did before struct metadata
+// was cached: parsing the fields and naming their columns for every row.
+func structSlicePerRow(r *Rows, out *[]rowsUser) error {
+	defer r.Raw.Close()
+	cc, err := r.Raw.Columns()
+	if err != nil {
+		return err
+	}
+	rt := reflect.TypeOf(rowsUser{})
+	for r.Raw.Next() {
+		var item rowsUser
+		rv := reflect.ValueOf(&item).Elem()
+		fieldMap := map[string]interface{}{}
+		for _, f := range parseFields(rt, nil, nil) {
+			fieldMap[f.column(nil)] = rv.FieldByIndex(f.Index).Addr().Interface()
+		}
+		targets := make([]interface{}, len(cc))
+		for i, c := range cc {
+			if v, ok := fieldMap[c]; ok {
+				targets[i] = v
+			} else {
+				targets[i] = new(interface{})
+			}
+		}
+		if err := r.Raw.Scan(targets...); err != nil {
+			return err
+		}
+		*out = append(*out, item)
+	}
+	return r.Raw.Err()
+}
+
+func BenchmarkStructSlice(b *testing.B) {
+	raw := openRowsDB(b, 1000)
+	b.Run("cached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			var users []rowsUser
+			if err := queryRows(b, raw, "select * from user").StructSlice(&users); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("per-row", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			var users []rowsUser
+			if err := structSlicePerRow(queryRows(b, raw, "select * from user"), &users); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func assertClosed(t *testing.T, r *Rows) {
+	t.Helper()
+	if _, err := r.Raw.Columns(); err == nil {
+		t.Fatal("rows are not closed")
+	}
+}
+
+func TestRowsStruct(t *testing.T) {
+	raw := openRowsDB(t, 3)
+	r := queryRows(t, raw, "select * from user where id = ?", 2)
+	var u rowsUser
+	if err := r.Struct(&u); err != nil {
+		t.Fatal(err)
+	}
+	want := rowsUser{ID: 2, UserName: "u", Email: "e", Age: 2, CreatedBy: "c"}
+	if u != want {
+		t.Fatalf("got %+v, want %+v", u, want)
+	}
+	assertClosed(t, r)
+}
+
+func TestRowsStructSlice(t *testing.T) {
+	raw := openRowsDB(t, 3)
+	var users []rowsUser
+	r := queryRows(t, raw, "select id, age from user order by id")
+	if err := r.StructSlice(&users); err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 3 {
+		t.Fatalf("got %d users, want 3", len(users))
+	}
+	for i, u := range users {
+		if u.ID != int64(i+1) || u.Age != i+1 {
+			t.Fatalf("user %d: got %+v", i, u)
+		}
+	}
+	assertClosed(t, r)
+}
+
+func TestRowsStructPointerSlice(t *testing.T) {
+	raw := openRowsDB(t, 3)
+	var users []*rowsUser
+	r := queryRows(t, raw, "select id, age from user order by id")
+	if err := r.StructSlice(&users); err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 3 {
+		t.Fatalf("got %d users, want 3", len(users))
+	}
+	for i, u := range users {
+		if u.ID != int64(i+1) || u.Age != i+1 {
+			t.Fatalf("user %d: got %+v", i, u)
+		}
+	}
+	if users[0] == users[1] {
+		t.Fatal("rows share the same struct")
+	}
+}
+
+func TestRowsBindStructSlice(t *testing.T) {
+	raw := openRowsDB(t, 2)
+	var users []rowsUser
+	if err := queryRows(t, raw, "select id from user order by id").Bind(&users); err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 2 || users[1].ID != 2 {
+		t.Fatalf("got %+v", users)
+	}
+}
+
+func TestRowsScalar(t *testing.T) {
+	raw := openRowsDB(t, 3)
+	var n int
+	r := queryRows(t, raw, "select count(*) from user")
+	if err := r.Scalar(&n); err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Fatalf("got %d, want 3", n)
+	}
+	assertClosed(t, r)
+}
+
+func TestRowsScalarSlice(t *testing.T) {
+	raw := openRowsDB(t, 3)
+	var ids []int64
+	r := queryRows(t, raw, "select id from user order by id")
+	if err := r.ScalarSlice(&ids); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ids, []int64{1, 2, 3}) {
+		t.Fatalf("got %v", ids)
+	}
+	assertClosed(t, r)
+}
+
+func TestRowsMap(t *testing.T) {
+	raw := openRowsDB(t, 3)
+	r := queryRows(t, raw, "select id, email from user where id = 1")
+	m, err := r.Map()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(m, map[string]interface{}{"id": int64(1), "email": "e"}) {
+		t.Fatalf("got %v", m)
+	}
+	assertClosed(t, r)
+}
+
+func TestRowsMapSlice(t *testing.T) {
+	raw := openRowsDB(t, 3)
+	r := queryRows(t, raw, "select id from user order by id")
+	mm, err := r.MapSlice()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []map[string]interface{}{{"id": int64(1)}, {"id": int64(2)}, {"id": int64(3)}}
+	if !reflect.DeepEqual(mm, want) {
+		t.Fatalf("got %v", mm)
+	}
+	assertClosed(t, r)
+}
+
+func TestRowsNoRows(t *testing.T) {
+	raw := openRowsDB(t, 1)
+	query := "select * from user where id = 0"
+	var u rowsUser
+	if err := queryRows(t, raw, query).Struct(&u); err != sql.ErrNoRows {
+		t.Fatalf("struct: got %v", err)
+	}
+	var n int
+	if err := queryRows(t, raw, query).Scalar(&n); err != sql.ErrNoRows {
+		t.Fatalf("scalar: got %v", err)
+	}
+	if _, err := queryRows(t, raw, query).Map(); err != sql.ErrNoRows {
+		t.Fatalf("map: got %v", err)
+	}
+	var users []rowsUser
+	if err := queryRows(t, raw, query).StructSlice(&users); err != nil || len(users) != 0 {
+		t.Fatalf("struct slice: got %v, %v", users, err)
+	}
+}
+
+func TestRowsClosedOnError(t *testing.T) {
+	raw := openRowsDB(t, 2)
+	var users []rowsUser
+	r := queryRows(t, raw, "select 'x' as age")
+	if err := r.StructSlice(&users); err == nil {
+		t.Fatal("want scan error")
+	}
+	assertClosed(t, r)
+	var u rowsUser
+	r = queryRows(t, raw, "select 'x' as id")
+	if err := r.Struct(&u); err == nil {
+		t.Fatal("want scan error")
+	}
+	assertClosed(t, r)
+	var ids []int
+	r = queryRows(t, raw, "select 'x'")
+	if err := r.ScalarSlice(&ids); err == nil {
+		t.Fatal("want scan error")
+	}
+	assertClosed(t, r)
+	r = queryRows(t, raw, "select id from user")
+	if err := r.StructSlice(users); err == nil {
+		t.Fatal("want pointer type error")
+	}
+	assertClosed(t, r)
+}
+
+func TestRowsErr(t *testing.T) {
+	raw := openRowsDB(t, 0)
+	// abs overflows on the second row, which fails while stepping, not scanning.
+	query := "select abs(v) as id from (select 1 as v union all select -9223372036854775808) order by v desc"
+	var users []rowsUser
+	r := queryRows(t, raw, query)
+	if err := r.StructSlice(&users); err == nil {
+		t.Fatal("struct slice: want rows error")
+	}
+	assertClosed(t, r)
+	var ids []int64
+	if err := queryRows(t, raw, query).ScalarSlice(&ids); err == nil {
+		t.Fatal("scalar slice: want rows error")
+	}
+	if _, err := queryRows(t, raw, query).MapSlice(); err == nil {
+		t.Fatal("map slice: want rows error")
+	}
+	err := queryRows(t, raw, query).Scan(func(scan func(...interface{}) error, abort func()) error {
+		var id int64
+		return scan(&id)
+	})
+	if err == nil {
+		t.Fatal("scan: want rows error")
+	}
+}
+
+func TestRowsScanAbort(t *testing.T) {
+	raw := openRowsDB(t, 5)
+	var ids []int64
+	r := queryRows(t, raw, "select id from user order by id")
+	err := r.Scan(func(scan func(...interface{}) error, abort func()) error {
+		var id int64
+		if err := scan(&id); err != nil {
+			return err
+		}
+		ids = append(ids, id)
+		if len(ids) == 2 {
+			abort()
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ids, []int64{1, 2}) {
+		t.Fatalf("got %v", ids)
+	}
+	assertClosed(t, r)
+}
+
+type rowsBase struct {
+	ID        int64
+	CreatedBy string
+}
+
+type rowsAddress struct {
+	City   string
+	Street string
+}
+
+type rowsProfile struct {
+	Bio string
+	Age *int
+}
+
+type rowsNested struct {
+	rowsBase
+	Home    rowsAddress `bear:"prefix=addr_"`
+	Work    rowsAddress
+	Profile *rowsProfile
+}
+
+func TestRowsNestedStruct(t *testing.T) {
+	raw := openRowsDB(t, 0)
+	query := `select 1 as id, 'c' as created_by, 'h' as addr_city, 'hs' as addr_street,
+		'w' as work_city, 'ws' as work_street, null as profile_bio, ? as profile_age`
+	var u rowsNested
+	if err := queryRows(t, raw, query, 7).Struct(&u); err != nil {
+		t.Fatal(err)
+	}
+	if u.ID != 1 || u.CreatedBy != "c" {
+		t.Fatalf("embedded: got %+v", u.rowsBase)
+	}
+	if u.Home != (rowsAddress{City: "h", Street: "hs"}) || u.Work != (rowsAddress{City: "w", Street: "ws"}) {
+		t.Fatalf("prefixed: got %+v, %+v", u.Home, u.Work)
+	}
+	if u.Profile == nil || u.Profile.Bio != "" || u.Profile.Age == nil || *u.Profile.Age != 7 {
+		t.Fatalf("pointer: got %+v", u.Profile)
+	}
+
+	var uu []rowsNested
+	if err := queryRows(t, raw, query, nil).StructSlice(&uu); err != nil {
+		t.Fatal(err)
+	}
+	if len(uu) != 1 || uu[0].Profile != nil {
+		t.Fatalf("all null pointer columns: got %+v", uu)
+	}
+}
+
+func TestRowsStrict(t *testing.T) {
+	raw := openRowsDB(t, 1)
+	var u rowsUser
+	if err := queryRows(t, raw, "select * from user").Strict(true).Struct(&u); err != nil {
+		t.Fatalf("matching columns: %v", err)
+	}
+	err := queryRows(t, raw, "select *, 1 as extra from user").Strict(true).Struct(&u)
+	if err == nil || !strings.Contains(err.Error(), "unmapped columns [extra]") {
+		t.Fatalf("extra column: got %v", err)
+	}
+	var users []rowsUser
+	err = queryRows(t, raw, "select id, user_name, email, age from user").Strict(true).StructSlice(&users)
+	if err == nil || !strings.Contains(err.Error(), "unfilled fields [CreatedBy(created_by)]") {
+		t.Fatalf("missing column: got %v", err)
+	}
+	if err := queryRows(t, raw, "select *, 1 as extra from user").Struct(&u); err != nil {
+		t.Fatalf("not strict: %v", err)
+	}
+
+	// pointer fields and the fields of pointers to struct may be left unfilled.
+	var n rowsNested
+	query := "select 1 as id, 'c' as created_by, 'h' as addr_city, 'hs' as addr_street, 'w' as work_city, 'ws' as work_street"
+	if err := queryRows(t, raw, query).Strict(true).Struct(&n); err != nil {
+		t.Fatalf("optional fields: %v", err)
+	}
+}
+
+func TestDBStrict(t *testing.T) {
+	raw := openRowsDB(t, 1)
+	ctx := context.Background()
+	db := NewDB(raw, WithDBStrict(true))
+	var u rowsUser
+	if err := db.Query(ctx, NewTemplate("select *, 1 as extra from user"), &u); err == nil {
+		t.Fatal("db: want strict binding error")
+	}
+	tx, err := db.BeginTx(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	if err := tx.Query(ctx, NewTemplate("select *, 1 as extra from user"), &u); err == nil {
+		t.Fatal("tx: want strict binding error")
+	}
+}
+
+func TestCursor(t *testing.T) {
+	raw := openRowsDB(t, 3)
+	c, err := NewDB(raw).Iterate(context.Background(), NewTemplate("select * from user order by id"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	var ids []int64
+	for c.Next() {
+		var u rowsUser
+		if err := c.Scan(&u); err != nil {
+			t.Fatal(err)
+		}
+		var m map[string]interface{}
+		if err := c.Scan(&m); err != nil {
+			t.Fatal(err)
+		}
+		if m["id"] != u.ID {
+			t.Fatalf("map %v does not match struct %+v", m, u)
+		}
+		ids = append(ids, u.ID)
+	}
+	if err := c.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ids, []int64{1, 2, 3}) {
+		t.Fatalf("got %v", ids)
+	}
+}
+
+func TestCursorStrictErr(t *testing.T) {
+	raw := openRowsDB(t, 3)
+	c, err := NewDB(raw, WithDBStrict(true)).Iterate(context.Background(), NewTemplate("select *, 1 as extra from user"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if !c.Next() {
+		t.Fatal("want a row")
+	}
+	var u rowsUser
+	if err := c.Scan(&u); err == nil {
+		t.Fatal("want strict binding error")
+	}
+	if c.Next() || c.Err() == nil {
+		t.Fatal("want the cursor stopped on the binding error")
+	}
+}
+
+func TestEach(t *testing.T) {
+	raw := openRowsDB(t, 5)
+	db := NewDB(raw)
+	ctx := context.Background()
+	var ids []int64
+	err := Each(ctx, db, NewTemplate("select * from user order by id"), func(u rowsUser, abort func()) error {
+		ids = append(ids, u.ID)
+		if len(ids) == 2 {
+			abort()
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ids, []int64{1, 2}) {
+		t.Fatalf("got %v", ids)
+	}
+
+	var ptrs []*rowsUser
+	err = Each(ctx, db, NewTemplate("select id from user where id <= ?", 2), func(u *rowsUser, abort func()) error {
+		ptrs = append(ptrs, u)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ptrs) != 2 || ptrs[0] == ptrs[1] || ptrs[1].ID != 2 {
+		t.Fatalf("got %+v", ptrs)
+	}
+
+	stop := errors.New("stop")
+	err = Each(ctx, db, NewTemplate("select id from user"), func(id int64, abort func()) error {
+		return stop
+	})
+	if err != stop {
+		t.Fatalf("got %v, want the error of fn", err)
+	}
+	// the connection is released when fn fails, so the db is still usable.
+	if err := Each(ctx, db, NewTemplate("select id from user"), func(id int64, abort func()) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -1,8 +1,334 @@
 package bear
 
+import (
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
 const (
-	TagKey          = "bear"
-	TagChildKeyName = "name"
-	TagItemSep      = ","
-	TagKVSep        = "="
+	TagKey               = "bear"
+	TagChildKeyName      = "name"
+	TagChildKeyPK        = "pk"
+	TagChildKeyAutoIncr  = "autoincr"
+	TagChildKeyOmitEmpty = "omitempty"
+	TagChildKeyReadonly  = "readonly"
+	TagChildKeyDefault   = "default"
+	TagChildKeyType      = "type"
+	TagChildKeySize      = "size"
+	TagChildKeyNotNull   = "notnull"
+	TagChildKeyUnique    = "unique"
+	TagChildKeyIndex     = "index"
+	TagChildKeyPrefix    = "prefix"
+	TagIgnore            = "-"
+	TagItemSep           = ","
+	TagKVSep             = "="
+)
+
+// field is the schema of a struct field parsed from its bear tag,
+// e.g. `bear:"name=user_id,pk,autoincr"`.
+type field struct {
+	Index     []int
+	Name      string
+	Type      reflect.Type
+	Column    string
+	PK        bool
+	AutoIncr  bool
+	OmitEmpty bool
+	Readonly  bool
+	Default   string
+	SQLType   string
+	Size      int
+	NotNull   bool
+	Unique    bool
+	IndexName string
+	Prefix    string
+	Anonymous bool
+	Parents   []field
+}
+
+var fieldsCache sync.Map
+
+// structFields returns the exported fields of the struct type rt that are not
+// ignored by a "-" tag, parsed once per type. Anonymous struct fields are
+// flattened and named struct fields are mapped with a column prefix, the
+// prefix= tag or the field name followed by an underscore.
+func structFields(rt reflect.Type) []field {
+	for rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	if v, ok := fieldsCache.Load(rt); ok {
+		return v.([]field)
+	}
+	if rt.Kind() != reflect.Struct {
+		panic(newError("tags", "require struct type"))
+	}
+	v, _ := fieldsCache.LoadOrStore(rt, parseFields(rt, nil, nil))
+	return v.([]field)
+}
+
+func parseFields(rt reflect.Type, index []int, parents []field) []field {
+	r := make([]field, 0, rt.NumField())
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if sf.PkgPath != "" && !sf.Anonymous {
+			continue
+		}
+		tag := sf.Tag.Get(TagKey)
+		if tag == TagIgnore {
+			continue
+		}
+		f := field{Index: append(append([]int{}, index...), i), Name: sf.Name, Type: sf.Type, Anonymous: sf.Anonymous, Parents: parents}
+		for _, item := range strings.Split(tag, TagItemSep) {
+			kv := strings.SplitN(strings.TrimSpace(item), TagKVSep, 2)
+			k, v := kv[0], ""
+			if len(kv) > 1 {
+				v = kv[1]
+			}
+			switch k {
+			case TagChildKeyName:
+				f.Column = v
+			case TagChildKeyPK:
+				f.PK = true
+			case TagChildKeyAutoIncr:
+				f.AutoIncr = true
+			case TagChildKeyOmitEmpty:
+				f.OmitEmpty = true
+			case TagChildKeyReadonly:
+				f.Readonly = true
+			case TagChildKeyDefault:
+				f.Default = v
+			case TagChildKeyType:
+				f.SQLType = v
+			case TagChildKeySize:
+				f.Size, _ = strconv.Atoi(v)
+			case TagChildKeyNotNull:
+				f.NotNull = true
+			case TagChildKeyUnique:
+				f.Unique = true
+			case TagChildKeyIndex:
+				f.IndexName = v
+			case TagChildKeyPrefix:
+				f.Prefix = v
+			}
+		}
+		if !isNestedStruct(sf.Type) || (sf.Anonymous && f.Column != "") {
+			if sf.PkgPath == "" {
+				r = append(r, f)
+			}
+			continue
+		}
+		if isRecursiveField(f) || (sf.PkgPath != "" && sf.Type.Kind() == reflect.Ptr) {
+			continue
+		}
+		r = append(r, parseFields(derefType(sf.Type), f.Index, append(append([]field{}, parents...), f))...)
+	}
+	return r
+}
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	valuerType  = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
 )
+
+// isNestedStruct reports whether fields of type t are mapped to the columns of
+// the struct fields rather than to one column.
+func isNestedStruct(t reflect.Type) bool {
+	t = derefType(t)
+	if t.Kind() != reflect.Struct || t == timeType {
+		return false
+	}
+	return !t.Implements(valuerType) && !reflect.PtrTo(t).Implements(scannerType)
+}
+
+func isRecursiveField(f field) bool {
+	for _, p := range f.Parents {
+		if derefType(p.Type) == derefType(f.Type) {
+			return true
+		}
+	}
+	return false
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// optional reports whether f is behind a pointer to struct, which is only
+// allocated when one of its columns is not null.
+func (f field) optional() bool {
+	for _, p := range f.Parents {
+		if p.Type.Kind() == reflect.Ptr {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldByIndex returns the field of rv at index, false if it is behind a nil pointer.
+func fieldByIndex(rv reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 {
+			for rv.Kind() == reflect.Ptr {
+				if rv.IsNil() {
+					return reflect.Value{}, false
+				}
+				rv = rv.Elem()
+			}
+		}
+		rv = rv.Field(x)
+	}
+	return rv, true
+}
+
+// fieldByIndexAlloc returns the field of rv at index, allocating nil pointers on the way.
+func fieldByIndexAlloc(rv reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 {
+			for rv.Kind() == reflect.Ptr {
+				if rv.IsNil() {
+					rv.Set(reflect.New(rv.Type().Elem()))
+				}
+				rv = rv.Elem()
+			}
+		}
+		rv = rv.Field(x)
+	}
+	return rv
+}
+
+// keyField returns the autoincr field of fields, or else its only pk field.
+func keyField(fields []field) (field, bool) {
+	var pk []field
+	for _, f := range fields {
+		if f.AutoIncr {
+			return f, true
+		}
+		if f.PK {
+			pk = append(pk, f)
+		}
+	}
+	if len(pk) == 1 {
+		return pk[0], true
+	}
+	return field{}, false
+}
+
+// isNullOrZero reports whether the field value fv is null or zero: a nil
+// pointer, a driver.Valuer whose value is nil, or else the zero value, so
+// that a valid sql.NullInt64{0, true} and a pointer to 0 are not zero.
+func isNullOrZero(fv reflect.Value) bool {
+	if fv.Kind() == reflect.Ptr || fv.Kind() == reflect.Interface {
+		return fv.IsNil()
+	}
+	if v, ok := valuer(fv); ok {
+		value, err := v.Value()
+		return err == nil && value == nil
+	}
+	return fv.IsZero()
+}
+
+// fieldInterface returns the field value fv to pass as a query value, its
+// address when only the pointer implements driver.Valuer.
+func fieldInterface(fv reflect.Value) interface{} {
+	if fv.Kind() == reflect.Ptr && fv.IsNil() {
+		return nil
+	}
+	if v, ok := valuer(fv); ok {
+		return v
+	}
+	return fv.Interface()
+}
+
+func valuer(fv reflect.Value) (driver.Valuer, bool) {
+	if v, ok := fv.Interface().(driver.Valuer); ok {
+		return v, true
+	}
+	if !reflect.PtrTo(fv.Type()).Implements(valuerType) {
+		return nil, false
+	}
+	p := reflect.New(fv.Type())
+	p.Elem().Set(fv)
+	return p.Interface().(driver.Valuer), true
+}
+
+var columnsCache sync.Map
+
+type columnsKey struct {
+	rt     reflect.Type
+	naming uintptr
+}
+
+// columnSet is the fields of a struct type with their columns named by a naming.
+type columnSet struct {
+	fields  []field
+	columns []string
+	// byColumn is the index of the shallowest field of every column.
+	byColumn map[string]int
+}
+
+// cachedNamings are the namings whose function pointer identifies them. Other
+// namings may be closures or method values, which share the pointer of their
+// code whatever they capture, so their columns are not cached.
+var cachedNamings = map[uintptr]bool{
+	reflect.ValueOf(SnakeNaming).Pointer(): true,
+	reflect.ValueOf(CamelNaming).Pointer(): true,
+	reflect.ValueOf(ExactNaming).Pointer(): true,
+}
+
+// structColumns returns the fields of the struct type rt with their columns
+// named by n, resolved once per type for the predefined namings.
+func structColumns(rt reflect.Type, n Naming) *columnSet {
+	rt = derefType(rt)
+	n = n.orDefault()
+	key := columnsKey{rt: rt, naming: reflect.ValueOf(n).Pointer()}
+	cached := cachedNamings[key.naming]
+	if cached {
+		if v, ok := columnsCache.Load(key); ok {
+			return v.(*columnSet)
+		}
+	}
+	fields := structFields(rt)
+	cs := &columnSet{
+		fields:   fields,
+		columns:  make([]string, len(fields)),
+		byColumn: make(map[string]int, len(fields)),
+	}
+	for i, f := range fields {
+		c := f.column(n)
+		cs.columns[i] = c
+		if j, ok := cs.byColumn[c]; ok && len(fields[j].Index) <= len(f.Index) {
+			continue
+		}
+		cs.byColumn[c] = i
+	}
+	if !cached {
+		return cs
+	}
+	v, _ := columnsCache.LoadOrStore(key, cs)
+	return v.(*columnSet)
+}
+
+// column returns the column name of f, its name= tag or its name mapped by n.
+func (f field) column(n Naming) string {
+	prefix := ""
+	for _, p := range f.Parents {
+		if p.Prefix != "" {
+			prefix += p.Prefix
+		} else if !p.Anonymous {
+			prefix += n.orDefault()(p.Name) + "_"
+		}
+	}
+	if f.Column != "" {
+		return prefix + f.Column
+	}
+	return prefix + n.orDefault()(f.Name)
+}
@@ -8,23 +8,53 @@ import (
 type Template struct {
 	Format string
 	Values []interface{}
+	raw    bool
+	ident  bool
+	alias  string
 }
 
 func NewTemplate(format string, values ...interface{}) Template {
 	return Template{Format: format, Values: append([]interface{}{}, values...)}
 }
 
+// Ident returns a template of a table or column name, which builders quote
+// through the dialect, also when it is the value of another template.
+func Ident(name string) Template {
+	t := NewTemplate(name)
+	t.ident = true
+	return t
+}
+
+// Expr returns a template of a raw sql expression, which builders never quote.
+func Expr(format string, values ...interface{}) Template {
+	t := NewTemplate(format, values...)
+	t.raw = true
+	return t
+}
+
+// Subquery returns a template of the query built by b, which is rendered in
+// parentheses with the dialect of the enclosing builder.
+func Subquery(b *Builder) Template {
+	return Expr("?", b)
+}
+
+// As returns a copy of t which builders render with the alias.
+func (t Template) As(alias string) Template {
+	t.alias = alias
+	return t
+}
+
 func (t Template) Append(others ...Template) Template {
 	t2 := NewTemplate(t.Format, t.Values...)
 	for _, o := range others {
 		t2.Format += o.Format
-		t2.AppendValues(o.Values...)
+		t2.Values = append(t2.Values, o.Values...)
 	}
-	return t
+	return t2
 }
 
 func (t Template) Appendf(format string, values ...interface{}) Template {
-	return NewTemplate(format, t.AppendValues(values...))
+	return t.Append(NewTemplate(format, values...))
 }
 
 func (t Template) AppendValues(values ...interface{}) Template {
@@ -58,6 +88,129 @@ func (t Template) String() string {
 	return b.String()
 }
 
+// Rebind rewrites the "?" placeholders of t into the placeholder syntax of d,
+// numbering them from 1. Question marks inside quoted literals or identifiers are kept.
+func (t Template) Rebind(d Dialect) Template {
+	if !strings.Contains(t.Format, "?") {
+		return t
+	}
+	n := 0
+	format := replacePlaceholders(t.Format, quotePairs(d), func() string {
+		n++
+		return d.Placeholder(n)
+	})
+	return NewTemplate(format, t.Values...)
+}
+
+// Expand renders the templates and builders in the values of t with d and
+// rewrites its placeholders, as Builder.Build does, so that templates made of
+// condition constructors such as Eq can be run directly.
+func (t Template) Expand(d Dialect) Template {
+	return t.expand(d).Rebind(d)
+}
+
+func (t Template) nested() bool {
+	for _, v := range t.Values {
+		switch v.(type) {
+		case Template, *Builder:
+			return true
+		}
+	}
+	return false
+}
+
+// expand splices the values of t that are templates or builders into its format,
+// Ident templates are quoted, other templates are kept as sql, builders are
+// rendered with d as parenthesized subqueries.
+func (t Template) expand(d Dialect) Template {
+	if !t.nested() {
+		return t
+	}
+	values := make([]interface{}, 0, len(t.Values))
+	n := 0
+	format := replacePlaceholders(t.Format, quotePairs(d), func() string {
+		if n >= len(t.Values) {
+			return "?"
+		}
+		v := t.Values[n]
+		n++
+		switch v := v.(type) {
+		case Template:
+			if !v.ident {
+				v.raw = true
+			}
+			e := v.quote(d).expand(d)
+			values = append(values, e.Values...)
+			return e.Format
+		case *Builder:
+			e := v.build(d)
+			values = append(values, e.Values...)
+			return "(" + e.Format + ")"
+		default:
+			values = append(values, v)
+			return "?"
+		}
+	})
+	values = append(values, t.Values[n:]...)
+	return NewTemplate(format, values...)
+}
+
+// quotePairs returns the closing quote of every opening quote of d: string
+// literals and the quoted identifiers of d, e.g. "[" to "]" for sql server.
+func quotePairs(d Dialect) map[rune]rune {
+	pairs := map[rune]rune{'\'': '\''}
+	if q := []rune(d.QuoteIdent("x")); len(q) == 3 && q[1] == 'x' {
+		pairs[q[0]] = q[2]
+	}
+	return pairs
+}
+
+// replacePlaceholders replaces every "?" outside of the quotes of pairs in
+// format with the result of fn.
+func replacePlaceholders(format string, pairs map[rune]rune, fn func() string) string {
+	b := strings.Builder{}
+	var quote rune
+	for _, c := range format {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case pairs[c] != 0:
+			quote = pairs[c]
+		case c == '?':
+			b.WriteString(fn())
+			continue
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+func (t Template) quote(d Dialect) Template {
+	r := NewTemplate(t.Format, t.Values...)
+	if !t.raw {
+		r.Format = d.QuoteIdent(t.Format)
+	}
+	if t.alias != "" {
+		r.Format += " as " + d.QuoteIdent(t.alias)
+	}
+	return r
+}
+
+func toTemplate(i interface{}) Template {
+	switch v := i.(type) {
+	case string:
+		return Ident(v)
+	case Template:
+		return v
+	case *Builder:
+		return Subquery(v)
+	default:
+		panic(newError("template", "unsupported type %T", i))
+	}
+}
+
 func (t Template) Empty() bool {
 	return t.Format == "" && len(t.Values) == 0
 }
@@ -1,5 +1,7 @@
 package bear
 
+import "sort"
+
 func repeatString(s string, n int) []string {
 	r := make([]string, 0, n)
 	for i := 0; i < n; i++ {
@@ -7,3 +9,12 @@ func repeatString(s string, n int) []string {
 	}
 	return r
 }
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}